@@ -6,7 +6,11 @@
 package elib
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // Number of bits per bitmap element
@@ -30,9 +34,9 @@ func (b Bitmap) isInline() bool {
 	return !b.isPoolIndex()
 }
 
-// bothMemory is true iff both arguments are direct non-memory bitmaps
+// bothInline is true iff both arguments are direct non-memory bitmaps
 func bothInline(b, c Bitmap) bool {
-	return (b | c) >= 0
+	return (b | c).isInline()
 }
 
 func firstSet(x Bitmap) Bitmap {
@@ -83,6 +87,12 @@ var Bitmaps = &BitmapPool{}
 func (p *BitmapPool) new() uint {
 	bi := p.GetIndex()
 	p.Validate(bi)
+	// Clear bits left over from previous use so that reslicing within capacity gives zeros.
+	b := p.bitmaps[bi][:cap(p.bitmaps[bi])]
+	for i := range b {
+		b[i] = 0
+	}
+	p.bitmaps[bi] = b[:0]
 	return bi
 }
 
@@ -429,6 +439,210 @@ func (b Bitmap) Next(px *uint) bool {
 	return Bitmaps.Next(b, px)
 }
 
+// Words of inline or pool bitmap.
+func (p *BitmapPool) words(b Bitmap) []Bitmap {
+	if !b.isPoolIndex() {
+		return []Bitmap{b}
+	}
+	return p.bitmaps[^b]
+}
+
+func (p *BitmapPool) And(b Bitmap, c Bitmap) (r Bitmap) {
+	r = b
+	if bothInline(b, c) {
+		r &= c
+		return
+	}
+	cs := p.words(c)
+	if b.isInline() {
+		// Result is a subset of inline b.
+		if len(cs) > 0 {
+			r &= cs[0]
+		} else {
+			r = 0
+		}
+		return
+	}
+	bi := uint(^r)
+	bs := p.bitmaps[bi]
+	l := 0
+	for i := range bs {
+		if i < len(cs) {
+			bs[i] &= cs[i]
+		} else {
+			bs[i] = 0
+		}
+		if bs[i] != 0 {
+			l = i + 1
+		}
+	}
+	// Strip trailing 0s
+	p.bitmaps[bi] = bs[:l]
+	r = p.checkInline(r)
+	return
+}
+
+func (b Bitmap) And(c Bitmap) Bitmap { return Bitmaps.And(b, c) }
+
+func (p *BitmapPool) Xor(b Bitmap, c Bitmap) (r Bitmap) {
+	r = b
+	if bothInline(b, c) {
+		r ^= c
+		return
+	}
+	r = p.inlineToMem(r)
+	bi := uint(^r)
+	cs := p.words(c)
+	if len(cs) > 0 {
+		p.bitmaps[bi].Validate(uint(len(cs) - 1))
+	}
+	bs := p.bitmaps[bi]
+	l := 0
+	for i := range bs {
+		if i < len(cs) {
+			bs[i] ^= cs[i]
+		}
+		if bs[i] != 0 {
+			l = i + 1
+		}
+	}
+	// Strip trailing 0s
+	p.bitmaps[bi] = bs[:l]
+	r = p.checkInline(r)
+	return
+}
+
+func (b Bitmap) Xor(c Bitmap) Bitmap { return Bitmaps.Xor(b, c) }
+
+// Count gives number of set bits.
+func (p *BitmapPool) Count(b Bitmap) uint { return bitmapsCount(p.words(b)) }
+
+// AndCount gives number of bits set in both b and c.
+func (p *BitmapPool) AndCount(b, c Bitmap) uint { return bitmapsAndCount(p.words(b), p.words(c)) }
+
+// IsSubset is true if all bits set in b are also set in c.
+func (p *BitmapPool) IsSubset(b, c Bitmap) bool { return bitmapsIsSubset(p.words(b), p.words(c)) }
+
+func (p *BitmapPool) Equal(b, c Bitmap) bool { return b == c || bitmapsEqual(p.words(b), p.words(c)) }
+
+// FirstClear gives index of first unset bit.
+func (p *BitmapPool) FirstClear(b Bitmap) uint { return bitmapsFirstClear(p.words(b)) }
+
+// Rank gives number of set bits with index less than x.
+func (p *BitmapPool) Rank(b Bitmap, x uint) uint { return bitmapsRank(p.words(b), x) }
+
+// Select gives index of nth (starting from zero) set bit.
+func (p *BitmapPool) Select(b Bitmap, n uint) (x uint, ok bool) { return bitmapsSelect(p.words(b), n) }
+
+// NextSet gives index of first set bit at or after x.
+func (p *BitmapPool) NextSet(b Bitmap, x uint) (y uint, ok bool) {
+	return bitmapsNextSet(p.words(b), x)
+}
+
+func (b Bitmap) Count() uint                      { return Bitmaps.Count(b) }
+func (b Bitmap) AndCount(c Bitmap) uint           { return Bitmaps.AndCount(b, c) }
+func (b Bitmap) IsSubset(c Bitmap) bool           { return Bitmaps.IsSubset(b, c) }
+func (b Bitmap) Equal(c Bitmap) bool              { return Bitmaps.Equal(b, c) }
+func (b Bitmap) FirstClear() uint                 { return Bitmaps.FirstClear(b) }
+func (b Bitmap) Rank(x uint) uint                 { return Bitmaps.Rank(b, x) }
+func (b Bitmap) Select(n uint) (x uint, ok bool)  { return Bitmaps.Select(b, n) }
+func (b Bitmap) NextSet(x uint) (y uint, ok bool) { return Bitmaps.NextSet(b, x) }
+
+func bitmapsCount(b []Bitmap) (n uint) {
+	for i := range b {
+		n += NSetBits(Word(b[i]))
+	}
+	return
+}
+
+func bitmapsAndCount(b, c []Bitmap) (n uint) {
+	for i := 0; i < len(b) && i < len(c); i++ {
+		n += NSetBits(Word(b[i] & c[i]))
+	}
+	return
+}
+
+func bitmapsIsSubset(b, c []Bitmap) bool {
+	for i := range b {
+		x := b[i]
+		if i < len(c) {
+			x &^= c[i]
+		}
+		if x != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Missing words are treated as zero.
+func bitmapsEqual(b, c []Bitmap) bool {
+	if len(b) < len(c) {
+		b, c = c, b
+	}
+	for i := range b {
+		var x Bitmap
+		if i < len(c) {
+			x = c[i]
+		}
+		if b[i] != x {
+			return false
+		}
+	}
+	return true
+}
+
+func bitmapsFirstClear(b []Bitmap) uint {
+	for i := range b {
+		if x := ^b[i]; x != 0 {
+			return uint(i*bitmapBits) + minLog2(firstSet(x))
+		}
+	}
+	return uint(len(b) * bitmapBits)
+}
+
+// First set bit at or after x.
+func bitmapsNextSet(b []Bitmap, x uint) (y uint, ok bool) {
+	i, m := bitmapIndex(x)
+	for ; i < uint(len(b)); i++ {
+		// Clear bits before x in first word.
+		if w := b[i] &^ (m - 1); w != 0 {
+			return i*bitmapBits + minLog2(firstSet(w)), true
+		}
+		m = 1
+	}
+	return
+}
+
+func bitmapsRank(b []Bitmap, x uint) (n uint) {
+	i, m := bitmapIndex(x)
+	for j := uint(0); j < i && j < uint(len(b)); j++ {
+		n += NSetBits(Word(b[j]))
+	}
+	if i < uint(len(b)) {
+		n += NSetBits(Word(b[i] & (m - 1)))
+	}
+	return
+}
+
+func bitmapsSelect(b []Bitmap, n uint) (x uint, ok bool) {
+	for i := range b {
+		w := b[i]
+		c := NSetBits(Word(w))
+		if n >= c {
+			n -= c
+			continue
+		}
+		for ; n > 0; n-- {
+			w ^= firstSet(w)
+		}
+		x = uint(i*bitmapBits) + minLog2(firstSet(w))
+		ok = true
+		return
+	}
+	return
+}
+
 func (p *BitmapPool) String(b Bitmap) string {
 	s := "{"
 	p.ForeachSetBit(b, func(x uint) {
@@ -464,6 +678,161 @@ func (b Bitmap) HexString() string {
 	return Bitmaps.HexString(b)
 }
 
+// RangeString formats set bits as comma separated list of ranges (e.g. "1-4,7,32-63").
+func (p *BitmapPool) RangeString(b Bitmap) string {
+	s := ""
+	first, last := ^uint(0), ^uint(0)
+	flush := func() {
+		if first == ^uint(0) {
+			return
+		}
+		if len(s) > 0 {
+			s += ","
+		}
+		if first == last {
+			s += strconv.FormatUint(uint64(first), 10)
+		} else {
+			s += strconv.FormatUint(uint64(first), 10) + "-" + strconv.FormatUint(uint64(last), 10)
+		}
+	}
+	p.ForeachSetBit(b, func(x uint) {
+		if last != ^uint(0) && x == last+1 {
+			last = x
+			return
+		}
+		flush()
+		first, last = x, x
+	})
+	flush()
+	return s
+}
+
+func (b Bitmap) RangeString() string {
+	return Bitmaps.RangeString(b)
+}
+
+var (
+	ErrBitmapSyntax = errors.New("bitmap: expected range list or hex mask")
+	ErrBitmapRange  = errors.New("bitmap: range too large")
+)
+
+// Largest bit index accepted in range lists.
+const MaxParseBitmapIndex = 1<<24 - 1
+
+// Parse parses range list (e.g. "1-4,7,32-63"), hex mask (e.g. "0xff00") or
+// String format (e.g. "{1, 2, 3}") into new bitmap.
+// Range list indices must not exceed MaxParseBitmapIndex.
+func (p *BitmapPool) Parse(s string) (b Bitmap, err error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		h := s[2:]
+		if len(h) == 0 {
+			return 0, ErrBitmapSyntax
+		}
+		for i := range h {
+			d, err := strconv.ParseUint(h[len(h)-1-i:len(h)-i], 16, 8)
+			if err != nil {
+				p.Free(b)
+				return 0, ErrBitmapSyntax
+			}
+			for j := uint(0); j < 4; j++ {
+				if d&(1<<j) != 0 {
+					b = p.Set(b, 4*uint(i)+j)
+				}
+			}
+		}
+		return
+	}
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = s[1 : len(s)-1]
+	}
+	if strings.TrimSpace(s) == "" {
+		return
+	}
+	for _, r := range strings.Split(s, ",") {
+		var x, y uint64
+		lo, hi := r, r
+		if i := strings.Index(r, "-"); i >= 0 {
+			lo, hi = r[:i], r[i+1:]
+		}
+		x, err = strconv.ParseUint(strings.TrimSpace(lo), 10, 0)
+		if err == nil {
+			y, err = strconv.ParseUint(strings.TrimSpace(hi), 10, 0)
+		}
+		if err != nil || y < x {
+			p.Free(b)
+			return 0, ErrBitmapSyntax
+		}
+		if y > MaxParseBitmapIndex {
+			p.Free(b)
+			return 0, ErrBitmapRange
+		}
+		for ; ; x++ {
+			b = p.Set(b, uint(x))
+			if x == y {
+				break
+			}
+		}
+	}
+	return
+}
+
+// ParseBitmap parses bitmap (see BitmapPool.Parse).
+func ParseBitmap(s string) (Bitmap, error) {
+	return Bitmaps.Parse(s)
+}
+
+// MarshalText formats bitmap as range list.
+func (b Bitmap) MarshalText() ([]byte, error) {
+	return []byte(b.RangeString()), nil
+}
+
+// UnmarshalText replaces *b with parsed bitmap.  Previous value of *b is not freed.
+func (b *Bitmap) UnmarshalText(text []byte) (err error) {
+	var r Bitmap
+	if r, err = ParseBitmap(string(text)); err == nil {
+		*b = r
+	}
+	return
+}
+
+// MarshalBinary encodes bitmap as little endian bytes with trailing zero bytes removed.
+func (p *BitmapPool) MarshalBinary(b Bitmap) []byte {
+	w := p.words(b)
+	data := make([]byte, 8*len(w))
+	for i := range w {
+		binary.LittleEndian.PutUint64(data[8*i:], uint64(w[i]))
+	}
+	l := len(data)
+	for l > 0 && data[l-1] == 0 {
+		l--
+	}
+	return data[:l]
+}
+
+// UnmarshalBinary decodes little endian bytes into new bitmap.
+func (p *BitmapPool) UnmarshalBinary(data []byte) (b Bitmap) {
+	for i, d := range data {
+		for j := uint(0); d != 0; j++ {
+			if d&1 != 0 {
+				b = p.Set(b, 8*uint(i)+j)
+			}
+			d >>= 1
+		}
+	}
+	return
+}
+
+func (b Bitmap) MarshalBinary() ([]byte, error) {
+	return Bitmaps.MarshalBinary(b), nil
+}
+
+// UnmarshalBinary replaces *b with decoded bitmap.  Previous value of *b is not freed.
+func (b *Bitmap) UnmarshalBinary(data []byte) error {
+	*b = Bitmaps.UnmarshalBinary(data)
+	return nil
+}
+
 func (bm BitmapVec) Get(x uint) (v bool) {
 	i, m := bitmapIndex(x)
 	if i < uint(len(bm)) {
@@ -496,3 +865,50 @@ func (bm *BitmapVec) Alloc(nBits uint) {
 		bm.Validate(i)
 	}
 }
+
+func (bm BitmapVec) Count() uint                      { return bitmapsCount(bm) }
+func (bm BitmapVec) AndCount(c BitmapVec) uint        { return bitmapsAndCount(bm, c) }
+func (bm BitmapVec) IsSubset(c BitmapVec) bool        { return bitmapsIsSubset(bm, c) }
+func (bm BitmapVec) Equal(c BitmapVec) bool           { return bitmapsEqual(bm, c) }
+func (bm BitmapVec) FirstClear() uint                 { return bitmapsFirstClear(bm) }
+func (bm BitmapVec) Rank(x uint) uint                 { return bitmapsRank(bm, x) }
+func (bm BitmapVec) Select(n uint) (x uint, ok bool)  { return bitmapsSelect(bm, n) }
+func (bm BitmapVec) NextSet(x uint) (y uint, ok bool) { return bitmapsNextSet(bm, x) }
+
+// Or sets bits set in c, growing bitmap as needed.
+func (bm *BitmapVec) Or(c BitmapVec) {
+	if len(c) > 0 {
+		bm.Validate(uint(len(c) - 1))
+	}
+	for i := range c {
+		(*bm)[i] |= c[i]
+	}
+}
+
+// Xor inverts bits set in c, growing bitmap as needed.
+func (bm *BitmapVec) Xor(c BitmapVec) {
+	if len(c) > 0 {
+		bm.Validate(uint(len(c) - 1))
+	}
+	for i := range c {
+		(*bm)[i] ^= c[i]
+	}
+}
+
+// And clears bits not set in c.
+func (bm BitmapVec) And(c BitmapVec) {
+	for i := range bm {
+		if i < len(c) {
+			bm[i] &= c[i]
+		} else {
+			bm[i] = 0
+		}
+	}
+}
+
+// AndNot clears bits set in c.
+func (bm BitmapVec) AndNot(c BitmapVec) {
+	for i := 0; i < len(bm) && i < len(c); i++ {
+		bm[i] &^= c[i]
+	}
+}
@@ -0,0 +1,691 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+// RoaringBitmap is a compressed bitmap for large sparse sets of 32 bit indices.
+// Indices are split into 64K chunks each stored as sorted array, dense bitmap or list of runs
+// whichever is smallest.  Binary format is the standard roaring bitmap serialization format.
+type RoaringBitmap struct {
+	// Sorted high 16 bits of indices for each container.
+	keys []uint16
+
+	containers []roaringContainer
+}
+
+type roaringKind uint8
+
+const (
+	roaringKindArray roaringKind = iota
+	roaringKindBitmap
+	roaringKindRun
+)
+
+const (
+	// Maximum cardinality of array containers.
+	roaringArrayMax = 4096
+
+	// Number of 64 bit words in bitmap container.
+	roaringBitmapWords = 1 << 16 / 64
+)
+
+type roaringRun struct{ start, last uint16 }
+
+type roaringContainer struct {
+	kind roaringKind
+
+	// Number of set bits.
+	n uint32
+
+	// Sorted values for array containers.
+	array []uint16
+
+	// Bits for bitmap containers.
+	bitmap []uint64
+
+	// Sorted non-overlapping runs for run containers.
+	runs []roaringRun
+}
+
+func roaringSplit(x uint) (hi, lo uint16) {
+	if x>>32 != 0 {
+		panic(fmt.Errorf("roaring bitmap index %d out of range", x))
+	}
+	return uint16(x >> 16), uint16(x)
+}
+
+func (c *roaringContainer) searchArray(lo uint16) (i int, ok bool) {
+	i = sort.Search(len(c.array), func(j int) bool { return c.array[j] >= lo })
+	ok = i < len(c.array) && c.array[i] == lo
+	return
+}
+
+func (c *roaringContainer) searchRuns(lo uint16) (i int, ok bool) {
+	i = sort.Search(len(c.runs), func(j int) bool { return c.runs[j].last >= lo })
+	ok = i < len(c.runs) && c.runs[i].start <= lo
+	return
+}
+
+func (c *roaringContainer) get(lo uint16) (v bool) {
+	switch c.kind {
+	case roaringKindArray:
+		_, v = c.searchArray(lo)
+	case roaringKindBitmap:
+		v = c.bitmap[lo/64]&(1<<(lo%64)) != 0
+	case roaringKindRun:
+		_, v = c.searchRuns(lo)
+	}
+	return
+}
+
+func (c *roaringContainer) foreach(hi uint16, fn func(x uint)) {
+	base := uint(hi) << 16
+	switch c.kind {
+	case roaringKindArray:
+		for _, lo := range c.array {
+			fn(base + uint(lo))
+		}
+	case roaringKindBitmap:
+		for i, w := range c.bitmap {
+			for w != 0 {
+				f := w & -w
+				fn(base + uint(i*64) + MinLog2(Word(f)))
+				w ^= f
+			}
+		}
+	case roaringKindRun:
+		for _, r := range c.runs {
+			for x := uint(r.start); x <= uint(r.last); x++ {
+				fn(base + x)
+			}
+		}
+	}
+}
+
+// Next set bit >= lo.
+func (c *roaringContainer) next(lo uint) (x uint, ok bool) {
+	if lo > 0xffff {
+		return
+	}
+	switch c.kind {
+	case roaringKindArray:
+		if i, _ := c.searchArray(uint16(lo)); i < len(c.array) {
+			x, ok = uint(c.array[i]), true
+		}
+	case roaringKindBitmap:
+		i := lo / 64
+		w := c.bitmap[i] &^ (1<<(lo%64) - 1)
+		for {
+			if w != 0 {
+				x, ok = i*64+MinLog2(Word(w&-w)), true
+				return
+			}
+			if i++; i >= roaringBitmapWords {
+				return
+			}
+			w = c.bitmap[i]
+		}
+	case roaringKindRun:
+		if i, _ := c.searchRuns(uint16(lo)); i < len(c.runs) {
+			x, ok = uint(c.runs[i].start), true
+			if x < lo {
+				x = lo
+			}
+		}
+	}
+	return
+}
+
+func (c *roaringContainer) toBitmap() {
+	b := make([]uint64, roaringBitmapWords)
+	c.foreach(0, func(x uint) { b[x/64] |= 1 << (x % 64) })
+	c.kind, c.bitmap, c.array, c.runs = roaringKindBitmap, b, nil, nil
+}
+
+func (c *roaringContainer) toArray() {
+	a := make([]uint16, 0, c.n)
+	c.foreach(0, func(x uint) { a = append(a, uint16(x)) })
+	c.kind, c.array, c.bitmap, c.runs = roaringKindArray, a, nil, nil
+}
+
+// Choose array or bitmap representation based on cardinality.
+func (c *roaringContainer) normalize() {
+	switch {
+	case c.kind == roaringKindRun:
+		if c.n <= roaringArrayMax {
+			c.toArray()
+		} else {
+			c.toBitmap()
+		}
+	case c.kind == roaringKindArray && c.n > roaringArrayMax:
+		c.toBitmap()
+	case c.kind == roaringKindBitmap && c.n <= roaringArrayMax:
+		c.toArray()
+	}
+}
+
+func (c *roaringContainer) set(lo uint16) (old bool) {
+	if c.kind == roaringKindRun {
+		if old = c.get(lo); old {
+			return
+		}
+		c.normalize()
+	}
+	switch c.kind {
+	case roaringKindArray:
+		i, ok := c.searchArray(lo)
+		if old = ok; old {
+			return
+		}
+		c.array = append(c.array, 0)
+		copy(c.array[i+1:], c.array[i:])
+		c.array[i] = lo
+	case roaringKindBitmap:
+		w, m := &c.bitmap[lo/64], uint64(1)<<(lo%64)
+		if old = *w&m != 0; old {
+			return
+		}
+		*w |= m
+	}
+	c.n++
+	c.normalize()
+	return
+}
+
+func (c *roaringContainer) unset(lo uint16) (old bool) {
+	if old = c.get(lo); !old {
+		return
+	}
+	if c.kind == roaringKindRun {
+		c.normalize()
+	}
+	switch c.kind {
+	case roaringKindArray:
+		i, _ := c.searchArray(lo)
+		c.array = append(c.array[:i], c.array[i+1:]...)
+	case roaringKindBitmap:
+		c.bitmap[lo/64] &^= 1 << (lo % 64)
+	}
+	c.n--
+	c.normalize()
+	return
+}
+
+func (c *roaringContainer) or(d *roaringContainer) {
+	if c.kind == roaringKindArray && d.kind == roaringKindArray {
+		a := make([]uint16, 0, len(c.array)+len(d.array))
+		i, j := 0, 0
+		for i < len(c.array) || j < len(d.array) {
+			switch {
+			case j >= len(d.array) || (i < len(c.array) && c.array[i] < d.array[j]):
+				a = append(a, c.array[i])
+				i++
+			case i >= len(c.array) || d.array[j] < c.array[i]:
+				a = append(a, d.array[j])
+				j++
+			default:
+				a = append(a, c.array[i])
+				i++
+				j++
+			}
+		}
+		c.array = a
+		c.n = uint32(len(a))
+		c.normalize()
+		return
+	}
+	if c.kind != roaringKindBitmap {
+		c.toBitmap()
+	}
+	if d.kind == roaringKindBitmap {
+		for i := range c.bitmap {
+			c.bitmap[i] |= d.bitmap[i]
+		}
+	} else {
+		d.foreach(0, func(x uint) { c.bitmap[x/64] |= 1 << (x % 64) })
+	}
+	c.n = 0
+	for i := range c.bitmap {
+		c.n += uint32(NSetBits(Word(c.bitmap[i])))
+	}
+	c.normalize()
+}
+
+func (c *roaringContainer) andNot(d *roaringContainer) {
+	if c.kind == roaringKindRun {
+		c.normalize()
+	}
+	switch c.kind {
+	case roaringKindArray:
+		a := c.array[:0]
+		for _, x := range c.array {
+			if !d.get(x) {
+				a = append(a, x)
+			}
+		}
+		c.array = a
+		c.n = uint32(len(a))
+	case roaringKindBitmap:
+		d.foreach(0, func(x uint) {
+			w, m := &c.bitmap[x/64], uint64(1)<<(x%64)
+			if *w&m != 0 {
+				*w &^= m
+				c.n--
+			}
+		})
+	}
+	c.normalize()
+}
+
+func (c *roaringContainer) nRuns() (n int) {
+	prev := -2
+	c.foreach(0, func(x uint) {
+		if int(x) != prev+1 {
+			n++
+		}
+		prev = int(x)
+	})
+	return
+}
+
+// Convert to run container when smaller.
+func (c *roaringContainer) optimize() {
+	if c.kind == roaringKindRun {
+		return
+	}
+	nr := c.nRuns()
+	size := 2 * int(c.n)
+	if c.kind == roaringKindBitmap {
+		size = 8 * roaringBitmapWords
+	}
+	if 2+4*nr >= size {
+		return
+	}
+	runs := make([]roaringRun, 0, nr)
+	c.foreach(0, func(x uint) {
+		if l := len(runs); l > 0 && uint(runs[l-1].last)+1 == x {
+			runs[l-1].last = uint16(x)
+		} else {
+			runs = append(runs, roaringRun{start: uint16(x), last: uint16(x)})
+		}
+	})
+	c.kind, c.runs, c.array, c.bitmap = roaringKindRun, runs, nil, nil
+}
+
+func (c *roaringContainer) dup() (d roaringContainer) {
+	d = *c
+	d.array = append([]uint16(nil), c.array...)
+	d.bitmap = append([]uint64(nil), c.bitmap...)
+	d.runs = append([]roaringRun(nil), c.runs...)
+	return
+}
+
+func (b *RoaringBitmap) search(hi uint16) (i int, ok bool) {
+	i = sort.Search(len(b.keys), func(j int) bool { return b.keys[j] >= hi })
+	ok = i < len(b.keys) && b.keys[i] == hi
+	return
+}
+
+func (b *RoaringBitmap) insert(i int, hi uint16, c roaringContainer) {
+	b.keys = append(b.keys, 0)
+	copy(b.keys[i+1:], b.keys[i:])
+	b.keys[i] = hi
+	b.containers = append(b.containers, roaringContainer{})
+	copy(b.containers[i+1:], b.containers[i:])
+	b.containers[i] = c
+}
+
+func (b *RoaringBitmap) remove(i int) {
+	b.keys = append(b.keys[:i], b.keys[i+1:]...)
+	b.containers = append(b.containers[:i], b.containers[i+1:]...)
+}
+
+func (b *RoaringBitmap) Get(x uint) (v bool) {
+	hi, lo := roaringSplit(x)
+	if i, ok := b.search(hi); ok {
+		v = b.containers[i].get(lo)
+	}
+	return
+}
+
+// Set2 sets bit x returning its old value.
+func (b *RoaringBitmap) Set2(x uint) (old bool) {
+	hi, lo := roaringSplit(x)
+	i, ok := b.search(hi)
+	if !ok {
+		b.insert(i, hi, roaringContainer{})
+	}
+	return b.containers[i].set(lo)
+}
+
+func (b *RoaringBitmap) Set(x uint) { b.Set2(x) }
+
+// Unset2 clears bit x returning its old value.
+func (b *RoaringBitmap) Unset2(x uint) (old bool) {
+	hi, lo := roaringSplit(x)
+	i, ok := b.search(hi)
+	if !ok {
+		return
+	}
+	c := &b.containers[i]
+	old = c.unset(lo)
+	if c.n == 0 {
+		b.remove(i)
+	}
+	return
+}
+
+func (b *RoaringBitmap) Unset(x uint) { b.Unset2(x) }
+
+// Or sets all bits set in c.
+func (b *RoaringBitmap) Or(c *RoaringBitmap) {
+	for j, hi := range c.keys {
+		i, ok := b.search(hi)
+		if !ok {
+			b.insert(i, hi, c.containers[j].dup())
+			b.containers[i].normalize()
+		} else {
+			b.containers[i].or(&c.containers[j])
+		}
+	}
+}
+
+// AndNot clears all bits set in c.
+func (b *RoaringBitmap) AndNot(c *RoaringBitmap) {
+	for j, hi := range c.keys {
+		if i, ok := b.search(hi); ok {
+			bc := &b.containers[i]
+			bc.andNot(&c.containers[j])
+			if bc.n == 0 {
+				b.remove(i)
+			}
+		}
+	}
+}
+
+func (b *RoaringBitmap) Dup() (c *RoaringBitmap) {
+	c = &RoaringBitmap{keys: append([]uint16(nil), b.keys...)}
+	c.containers = make([]roaringContainer, len(b.containers))
+	for i := range b.containers {
+		c.containers[i] = b.containers[i].dup()
+	}
+	return
+}
+
+// Count gives number of set bits.
+func (b *RoaringBitmap) Count() (n uint) {
+	for i := range b.containers {
+		n += uint(b.containers[i].n)
+	}
+	return
+}
+
+// Optimize converts containers to runs where this saves memory.
+func (b *RoaringBitmap) Optimize() {
+	for i := range b.containers {
+		b.containers[i].optimize()
+	}
+}
+
+func (b *RoaringBitmap) ForeachSetBit(fn func(uint)) {
+	for i := range b.containers {
+		b.containers[i].foreach(b.keys[i], fn)
+	}
+}
+
+// Next sets *px to next set bit after *px (or first set bit if *px is ^uint(0)).
+func (b *RoaringBitmap) Next(px *uint) (ok bool) {
+	x := *px + 1
+	if *px == ^uint(0) {
+		x = 0
+	}
+	hi, lo := x>>16, x&0xffff
+	i := sort.Search(len(b.keys), func(j int) bool { return uint(b.keys[j]) >= hi })
+	for ; i < len(b.keys); i++ {
+		if uint(b.keys[i]) > hi {
+			lo = 0
+		}
+		var y uint
+		if y, ok = b.containers[i].next(lo); ok {
+			*px = uint(b.keys[i])<<16 + y
+			return
+		}
+	}
+	*px = ^uint(0)
+	return
+}
+
+func (b *RoaringBitmap) String() string {
+	s := "{"
+	b.ForeachSetBit(func(x uint) {
+		if len(s) > 1 {
+			s += ", "
+		}
+		s += fmt.Sprintf("%d", x)
+	})
+	s += "}"
+	return s
+}
+
+// Standard roaring format constants.
+const (
+	roaringSerialCookieNoRun    = 12346
+	roaringSerialCookie         = 12347
+	roaringNoOffsetThreshold    = 4
+	roaringSerialMaxContainers  = 1 << 16
+	roaringSerialBitmapBytes    = 8 * roaringBitmapWords
+	roaringSerialDescriptorSize = 4
+)
+
+var ErrRoaringCorrupt = errors.New("roaring bitmap: corrupt binary data")
+
+func (c *roaringContainer) serialSize() int {
+	switch c.kind {
+	case roaringKindArray:
+		return 2 * len(c.array)
+	case roaringKindBitmap:
+		return roaringSerialBitmapBytes
+	default:
+		return 2 + 4*len(c.runs)
+	}
+}
+
+// MarshalBinary encodes bitmap in standard roaring format.
+func (b *RoaringBitmap) MarshalBinary() (data []byte, err error) {
+	n := len(b.keys)
+	hasRun := false
+	for i := range b.containers {
+		if b.containers[i].kind == roaringKindRun {
+			hasRun = true
+		}
+	}
+	le := binary.LittleEndian
+
+	var hdr []byte
+	if hasRun {
+		hdr = make([]byte, 4+(n+7)/8)
+		le.PutUint32(hdr, uint32(roaringSerialCookie)|uint32(n-1)<<16)
+		for i := range b.containers {
+			if b.containers[i].kind == roaringKindRun {
+				hdr[4+i/8] |= 1 << uint(i%8)
+			}
+		}
+	} else {
+		hdr = make([]byte, 8)
+		le.PutUint32(hdr, roaringSerialCookieNoRun)
+		le.PutUint32(hdr[4:], uint32(n))
+	}
+	withOffsets := !hasRun || n >= roaringNoOffsetThreshold
+
+	l := len(hdr) + roaringSerialDescriptorSize*n
+	if withOffsets {
+		l += 4 * n
+	}
+	offset := l
+	for i := range b.containers {
+		l += b.containers[i].serialSize()
+	}
+
+	data = make([]byte, l)
+	i := copy(data, hdr)
+	for j := range b.containers {
+		le.PutUint16(data[i:], b.keys[j])
+		le.PutUint16(data[i+2:], uint16(b.containers[j].n-1))
+		i += roaringSerialDescriptorSize
+	}
+	if withOffsets {
+		for j := range b.containers {
+			le.PutUint32(data[i:], uint32(offset))
+			offset += b.containers[j].serialSize()
+			i += 4
+		}
+	}
+	for j := range b.containers {
+		c := &b.containers[j]
+		switch c.kind {
+		case roaringKindArray:
+			for _, x := range c.array {
+				le.PutUint16(data[i:], x)
+				i += 2
+			}
+		case roaringKindBitmap:
+			for _, w := range c.bitmap {
+				le.PutUint64(data[i:], w)
+				i += 8
+			}
+		case roaringKindRun:
+			le.PutUint16(data[i:], uint16(len(c.runs)))
+			i += 2
+			for _, r := range c.runs {
+				le.PutUint16(data[i:], r.start)
+				le.PutUint16(data[i+2:], r.last-r.start)
+				i += 4
+			}
+		}
+	}
+	return
+}
+
+// UnmarshalBinary decodes bitmap in standard roaring format.
+func (b *RoaringBitmap) UnmarshalBinary(data []byte) (err error) {
+	le := binary.LittleEndian
+	if len(data) < 4 {
+		return ErrRoaringCorrupt
+	}
+	var (
+		n      int
+		isRun  []byte
+		i      int
+		cookie = le.Uint32(data)
+	)
+	switch {
+	case cookie&0xffff == roaringSerialCookie:
+		n = int(cookie>>16) + 1
+		i = 4 + (n+7)/8
+		if len(data) < i {
+			return ErrRoaringCorrupt
+		}
+		isRun = data[4:i]
+	case cookie == roaringSerialCookieNoRun:
+		if len(data) < 8 {
+			return ErrRoaringCorrupt
+		}
+		n = int(le.Uint32(data[4:]))
+		i = 8
+	default:
+		return fmt.Errorf("roaring bitmap: unknown cookie 0x%x", cookie)
+	}
+	if n > roaringSerialMaxContainers {
+		return ErrRoaringCorrupt
+	}
+	if len(data) < i+roaringSerialDescriptorSize*n {
+		return ErrRoaringCorrupt
+	}
+	desc := data[i:]
+	i += roaringSerialDescriptorSize * n
+	if isRun == nil || n >= roaringNoOffsetThreshold {
+		// Offsets are redundant since containers are stored in order.
+		i += 4 * n
+	}
+
+	var r RoaringBitmap
+	r.keys = make([]uint16, n)
+	r.containers = make([]roaringContainer, n)
+	for j := 0; j < n; j++ {
+		r.keys[j] = le.Uint16(desc[4*j:])
+		if j > 0 && r.keys[j] <= r.keys[j-1] {
+			return ErrRoaringCorrupt
+		}
+		c := &r.containers[j]
+		c.n = uint32(le.Uint16(desc[4*j+2:])) + 1
+		switch {
+		case isRun != nil && isRun[j/8]&(1<<uint(j%8)) != 0:
+			if len(data) < i+2 {
+				return ErrRoaringCorrupt
+			}
+			nr := int(le.Uint16(data[i:]))
+			i += 2
+			if nr == 0 || len(data) < i+4*nr {
+				return ErrRoaringCorrupt
+			}
+			c.kind = roaringKindRun
+			c.runs = make([]roaringRun, nr)
+			count := uint32(0)
+			for k := range c.runs {
+				s, l := le.Uint16(data[i:]), le.Uint16(data[i+2:])
+				if uint(s)+uint(l) > 0xffff {
+					return ErrRoaringCorrupt
+				}
+				// Runs must be sorted and must not overlap.
+				if k > 0 && s <= c.runs[k-1].last {
+					return ErrRoaringCorrupt
+				}
+				c.runs[k] = roaringRun{start: s, last: s + l}
+				count += uint32(l) + 1
+				i += 4
+			}
+			if count != c.n {
+				return ErrRoaringCorrupt
+			}
+		case c.n <= roaringArrayMax:
+			if len(data) < i+2*int(c.n) {
+				return ErrRoaringCorrupt
+			}
+			c.kind = roaringKindArray
+			c.array = make([]uint16, c.n)
+			for k := range c.array {
+				c.array[k] = le.Uint16(data[i:])
+				if k > 0 && c.array[k] <= c.array[k-1] {
+					return ErrRoaringCorrupt
+				}
+				i += 2
+			}
+		default:
+			if len(data) < i+roaringSerialBitmapBytes {
+				return ErrRoaringCorrupt
+			}
+			c.kind = roaringKindBitmap
+			c.bitmap = make([]uint64, roaringBitmapWords)
+			count := uint32(0)
+			for k := range c.bitmap {
+				c.bitmap[k] = le.Uint64(data[i:])
+				count += uint32(bits.OnesCount64(c.bitmap[k]))
+				i += 8
+			}
+			if count != c.n {
+				return ErrRoaringCorrupt
+			}
+		}
+	}
+	*b = r
+	return
+}
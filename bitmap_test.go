@@ -0,0 +1,349 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Random bitmap with bits set below max; returns bitmap and same bits as set.
+func randBitmap(max uint) (b Bitmap, s map[uint]bool) {
+	s = make(map[uint]bool)
+	n := rand.Intn(int(max))
+	for i := 0; i < n; i++ {
+		x := uint(rand.Intn(int(max)))
+		b = b.Set(x)
+		s[x] = true
+	}
+	return
+}
+
+func checkBitmap(t *testing.T, op string, b Bitmap, s map[uint]bool, max uint) {
+	for x := uint(0); x < max; x++ {
+		if got, want := b.Get(x), s[x]; got != want {
+			t.Fatalf("%s: bit %d got %v != want %v", op, x, got, want)
+		}
+	}
+}
+
+func TestBitmapAlgebra(t *testing.T) {
+	for iter := 0; iter < 1000; iter++ {
+		max := uint(1 + rand.Intn(300))
+		b, bs := randBitmap(max)
+		c, cs := randBitmap(max)
+
+		and, xor := make(map[uint]bool), make(map[uint]bool)
+		for x := range bs {
+			if cs[x] {
+				and[x] = true
+			} else {
+				xor[x] = true
+			}
+		}
+		for x := range cs {
+			if !bs[x] {
+				xor[x] = true
+			}
+		}
+		if got, want := b.AndCount(c), uint(len(and)); got != want {
+			t.Fatalf("and count %d != %d", got, want)
+		}
+		if got, want := b.IsSubset(c), len(and) == len(bs); got != want {
+			t.Fatalf("subset %v != %v", got, want)
+		}
+		if got, want := b.Count(), uint(len(bs)); got != want {
+			t.Fatalf("count %d != %d", got, want)
+		}
+
+		// Rank/select of every set bit.
+		n := uint(0)
+		for x := uint(0); x <= max; x++ {
+			if got := b.Rank(x); got != n {
+				t.Fatalf("rank %d: %d != %d", x, got, n)
+			}
+			if bs[x] {
+				if y, ok := b.Select(n); !ok || y != x {
+					t.Fatalf("select %d: %d %v != %d", n, y, ok, x)
+				}
+				n++
+			}
+		}
+		if _, ok := b.Select(n); ok {
+			t.Fatalf("select %d beyond last bit", n)
+		}
+
+		// Next set bit at or after every position.
+		next, nextOk := uint(0), false
+		for x := int(max) + bitmapBits; x >= 0; x-- {
+			if bs[uint(x)] {
+				next, nextOk = uint(x), true
+			}
+			if y, ok := b.NextSet(uint(x)); ok != nextOk || (ok && y != next) {
+				t.Fatalf("next set %d: %d %v != %d %v", x, y, ok, next, nextOk)
+			}
+		}
+
+		fc := uint(0)
+		for bs[fc] {
+			fc++
+		}
+		if got := b.FirstClear(); got != fc {
+			t.Fatalf("first clear %d != %d", got, fc)
+		}
+
+		x := b.Dup().Xor(c)
+		checkBitmap(t, "xor", x, xor, max)
+		if x.Equal(0) != (len(xor) == 0) {
+			t.Fatalf("xor equal zero")
+		}
+		x.Free()
+
+		a := b.Dup().And(c)
+		checkBitmap(t, "and", a, and, max)
+		if d := c.Dup().And(b); !a.Equal(d) {
+			t.Fatalf("and not commutative")
+		} else {
+			d.Free()
+		}
+
+		b.Free()
+		c.Free()
+		a.Free()
+	}
+}
+
+// Random roaring bitmap with n clustered bits (to exercise array, bitmap and run containers).
+func randRoaring(n int) (b *RoaringBitmap, s map[uint]bool) {
+	b, s = &RoaringBitmap{}, make(map[uint]bool)
+	for i := 0; i < n; i++ {
+		x := uint(rand.Intn(4)) << 16
+		switch rand.Intn(3) {
+		case 0:
+			x += uint(rand.Intn(1 << 16))
+		case 1:
+			x += uint(rand.Intn(1 << 8))
+		default:
+			x += 1000 + uint(i%8000)
+		}
+		b.Set(x)
+		s[x] = true
+	}
+	return
+}
+
+func checkRoaring(t *testing.T, op string, b *RoaringBitmap, s map[uint]bool) {
+	if got, want := b.Count(), uint(len(s)); got != want {
+		t.Fatalf("%s: count %d != %d", op, got, want)
+	}
+	for x := range s {
+		if !b.Get(x) {
+			t.Fatalf("%s: bit %d not set", op, x)
+		}
+	}
+	prev, n := ^uint(0), 0
+	for x := ^uint(0); b.Next(&x); n++ {
+		if !s[x] || (prev != ^uint(0) && x <= prev) {
+			t.Fatalf("%s: next %d after %d", op, x, prev)
+		}
+		prev = x
+	}
+	if n != len(s) {
+		t.Fatalf("%s: next visited %d != %d", op, n, len(s))
+	}
+}
+
+func TestRoaringBitmap(t *testing.T) {
+	for iter := 0; iter < 20; iter++ {
+		b, bs := randRoaring(rand.Intn(20000))
+		c, cs := randRoaring(rand.Intn(20000))
+		checkRoaring(t, "set", b, bs)
+
+		for x := range bs {
+			if rand.Intn(4) == 0 {
+				if !b.Unset2(x) {
+					t.Fatalf("unset %d: not set", x)
+				}
+				delete(bs, x)
+			}
+		}
+		checkRoaring(t, "unset", b, bs)
+
+		or := b.Dup()
+		or.Or(c)
+		os := make(map[uint]bool)
+		for x := range bs {
+			os[x] = true
+		}
+		for x := range cs {
+			os[x] = true
+		}
+		checkRoaring(t, "or", or, os)
+
+		andNot := b.Dup()
+		andNot.AndNot(c)
+		as := make(map[uint]bool)
+		for x := range bs {
+			if !cs[x] {
+				as[x] = true
+			}
+		}
+		checkRoaring(t, "and not", andNot, as)
+
+		or.Optimize()
+		checkRoaring(t, "optimize", or, os)
+		for _, r := range []*RoaringBitmap{b, or} {
+			data, err := r.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var d RoaringBitmap
+			if err = d.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			if r == b {
+				checkRoaring(t, "unmarshal", &d, bs)
+			} else {
+				checkRoaring(t, "unmarshal optimized", &d, os)
+			}
+		}
+	}
+}
+
+func TestRoaringBitmapFormat(t *testing.T) {
+	var b RoaringBitmap
+	b.Set(1)
+	b.Set(2)
+	b.Set(3)
+	data, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x3a, 0x30, 0, 0, // cookie
+		1, 0, 0, 0, // 1 container
+		0, 0, 2, 0, // key 0, cardinality 3
+		16, 0, 0, 0, // offset
+		1, 0, 2, 0, 3, 0,
+	}
+	if string(data) != string(want) {
+		t.Fatalf("got % x != want % x", data, want)
+	}
+
+	for x := uint(4); x <= 10; x++ {
+		b.Set(x)
+	}
+	b.Optimize()
+	data, _ = b.MarshalBinary()
+	want = []byte{
+		0x3b, 0x30, 0, 0, // cookie with 1 container
+		1,          // run container bitset
+		0, 0, 9, 0, // key 0, cardinality 10
+		1, 0, 1, 0, 9, 0, // 1 run: start 1 length 10
+	}
+	if string(data) != string(want) {
+		t.Fatalf("run: got % x != want % x", data, want)
+	}
+}
+
+func TestRoaringBitmapCorrupt(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		data []byte
+	}{
+		{"unsorted array", []byte{
+			0x3a, 0x30, 0, 0, 1, 0, 0, 0,
+			0, 0, 2, 0, 16, 0, 0, 0,
+			1, 0, 3, 0, 2, 0,
+		}},
+		{"duplicate array", []byte{
+			0x3a, 0x30, 0, 0, 1, 0, 0, 0,
+			0, 0, 2, 0, 16, 0, 0, 0,
+			1, 0, 2, 0, 2, 0,
+		}},
+		{"overlapping runs", []byte{
+			0x3b, 0x30, 0, 0, 1,
+			0, 0, 9, 0,
+			2, 0, 1, 0, 4, 0, 3, 0, 4, 0,
+		}},
+		{"unsorted runs", []byte{
+			0x3b, 0x30, 0, 0, 1,
+			0, 0, 9, 0,
+			2, 0, 20, 0, 4, 0, 1, 0, 4, 0,
+		}},
+		{"run cardinality", []byte{
+			0x3b, 0x30, 0, 0, 1,
+			0, 0, 9, 0,
+			1, 0, 1, 0, 4, 0,
+		}},
+	} {
+		var b RoaringBitmap
+		if err := b.UnmarshalBinary(c.data); err != ErrRoaringCorrupt {
+			t.Errorf("%s: expected corrupt error got %v", c.name, err)
+		}
+	}
+
+	// Bitmap container whose bits do not match cardinality.
+	var b RoaringBitmap
+	for x := uint(0); x <= 2*roaringArrayMax; x += 2 {
+		b.Set(x)
+	}
+	data, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] = 0xff
+	if err = b.UnmarshalBinary(data); err != ErrRoaringCorrupt {
+		t.Errorf("bitmap cardinality: expected corrupt error got %v", err)
+	}
+}
+
+func TestBitmapParse(t *testing.T) {
+	for _, c := range []struct{ in, ranges string }{
+		{"", ""},
+		{"1-4,7,32-63", "1-4,7,32-63"},
+		{"{1, 2, 3, 70}", "1-3,70"},
+		{"0x0", ""},
+		{"0xf0", "4-7"},
+		{"0x80000000000000000001", "0,79"},
+	} {
+		b, err := ParseBitmap(c.in)
+		if err != nil {
+			t.Fatalf("%q: %v", c.in, err)
+		}
+		if got := b.RangeString(); got != c.ranges {
+			t.Fatalf("%q: got %q != want %q", c.in, got, c.ranges)
+		}
+		b.Free()
+	}
+	for _, in := range []string{"x", "4-1", "1,,2", "0x", "0xg", "0-18446744073709551615", "16777216"} {
+		if _, err := ParseBitmap(in); err == nil {
+			t.Fatalf("%q: expected error", in)
+		}
+	}
+
+	for iter := 0; iter < 100; iter++ {
+		b, s := randBitmap(uint(1 + rand.Intn(300)))
+		text, _ := b.MarshalText()
+		var c Bitmap
+		if err := c.UnmarshalText(text); err != nil {
+			t.Fatal(err)
+		}
+		checkBitmap(t, "text", c, s, 300)
+		h, err := ParseBitmap(b.HexString())
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkBitmap(t, "hex", h, s, 300)
+		data, _ := b.MarshalBinary()
+		var d Bitmap
+		d.UnmarshalBinary(data)
+		checkBitmap(t, "binary", d, s, 300)
+		b.Free()
+		c.Free()
+		h.Free()
+		d.Free()
+	}
+}
@@ -172,3 +172,59 @@ func (s *BitCompressUint64) Compress(x uint64) (r uint64) {
 	r ^= q ^ (q >> 32)
 	return r
 }
+
+// Expand is the inverse of Compress: low bits of x are scattered to set bit positions of mask.
+func (s *BitCompressUint64) Expand(x uint64) (r uint64) {
+	r = x
+	q := s.state[6]
+	r = r&^q | (r<<32)&q
+	q = s.state[5]
+	r = r&^q | (r<<16)&q
+	q = s.state[4]
+	r = r&^q | (r<<8)&q
+	q = s.state[3]
+	r = r&^q | (r<<4)&q
+	q = s.state[2]
+	r = r&^q | (r<<2)&q
+	q = s.state[1]
+	r = r&^q | (r<<1)&q
+	return r & s.state[0]
+}
+
+func (s *BitCompressUint64) compressSlice(dst, src []uint64) {
+	if len(src) > len(dst) {
+		src = src[:len(dst)]
+	}
+	for i := range src {
+		dst[i] = s.Compress(src[i])
+	}
+}
+
+func (s *BitCompressUint64) expandSlice(dst, src []uint64) {
+	if len(src) > len(dst) {
+		src = src[:len(dst)]
+	}
+	for i := range src {
+		dst[i] = s.Expand(src[i])
+	}
+}
+
+// CompressSlice sets dst[i] to Compress(src[i]) for each element of shorter of dst and src.
+// Uses PEXT instruction when available.
+func (s *BitCompressUint64) CompressSlice(dst, src []uint64) {
+	if bitCompressHaveBMI2 {
+		pextSlice(dst, src, s.state[0])
+	} else {
+		s.compressSlice(dst, src)
+	}
+}
+
+// ExpandSlice sets dst[i] to Expand(src[i]) for each element of shorter of dst and src.
+// Uses PDEP instruction when available.
+func (s *BitCompressUint64) ExpandSlice(dst, src []uint64) {
+	if bitCompressHaveBMI2 {
+		pdepSlice(dst, src, s.state[0])
+	} else {
+		s.expandSlice(dst, src)
+	}
+}
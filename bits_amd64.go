@@ -0,0 +1,42 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+// BMI2 (PEXT and PDEP instructions) is CPUID leaf 7 EBX bit 8.
+// AMD (and Hygon) CPUs before Zen 3 (family 0x19) implement PEXT and PDEP in microcode
+// taking hundreds of cycles, so they use the generic code instead.
+func haveBMI2() bool {
+	max, ebx, ecx, edx := cpuid(0, 0)
+	if max < 7 {
+		return false
+	}
+	isAMD := ebx == 0x68747541 && edx == 0x69746e65 && ecx == 0x444d4163   // "AuthenticAMD"
+	isHygon := ebx == 0x6f677948 && edx == 0x6e65476e && ecx == 0x656e6975 // "HygonGenuine"
+	if isAMD || isHygon {
+		if cpuFamily() < 0x19 {
+			return false
+		}
+	}
+	_, ebx, _, _ = cpuid(7, 0)
+	return ebx&(1<<8) != 0
+}
+
+// Family from CPUID leaf 1 EAX: base family plus extended family when base is 0xf.
+func cpuFamily() uint32 {
+	eax, _, _, _ := cpuid(1, 0)
+	f := (eax >> 8) & 0xf
+	if f == 0xf {
+		f += (eax >> 20) & 0xff
+	}
+	return f
+}
+
+// Set when CPU supports PEXT and PDEP instructions.
+var bitCompressHaveBMI2 = haveBMI2()
+
+func pextSlice(dst, src []uint64, mask uint64)
+func pdepSlice(dst, src []uint64, mask uint64)
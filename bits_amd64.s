@@ -0,0 +1,54 @@
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB),4,$0-24
+	MOVL	eaxArg+0(FP), AX
+	MOVL	ecxArg+4(FP), CX
+	CPUID
+	MOVL	AX, eax+8(FP)
+	MOVL	BX, ebx+12(FP)
+	MOVL	CX, ecx+16(FP)
+	MOVL	DX, edx+20(FP)
+	RET
+
+// func pextSlice(dst, src []uint64, mask uint64)
+TEXT ·pextSlice(SB),4,$0-56
+	MOVQ	dst_base+0(FP), DI
+	MOVQ	dst_len+8(FP), CX
+	MOVQ	src_base+24(FP), SI
+	MOVQ	src_len+32(FP), DX
+	MOVQ	mask+48(FP), BX
+	CMPQ	DX, CX
+	CMOVQLT	DX, CX
+	TESTQ	CX, CX
+	JEQ	pextDone
+pextLoop:
+	MOVQ	(SI), AX
+	PEXTQ	BX, AX, AX
+	MOVQ	AX, (DI)
+	ADDQ	$8, SI
+	ADDQ	$8, DI
+	DECQ	CX
+	JNE	pextLoop
+pextDone:
+	RET
+
+// func pdepSlice(dst, src []uint64, mask uint64)
+TEXT ·pdepSlice(SB),4,$0-56
+	MOVQ	dst_base+0(FP), DI
+	MOVQ	dst_len+8(FP), CX
+	MOVQ	src_base+24(FP), SI
+	MOVQ	src_len+32(FP), DX
+	MOVQ	mask+48(FP), BX
+	CMPQ	DX, CX
+	CMOVQLT	DX, CX
+	TESTQ	CX, CX
+	JEQ	pdepDone
+pdepLoop:
+	MOVQ	(SI), AX
+	PDEPQ	BX, AX, AX
+	MOVQ	AX, (DI)
+	ADDQ	$8, SI
+	ADDQ	$8, DI
+	DECQ	CX
+	JNE	pdepLoop
+pdepDone:
+	RET
@@ -0,0 +1,13 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !amd64
+
+package elib
+
+// No PEXT/PDEP: slice functions always use generic code.
+var bitCompressHaveBMI2 = false
+
+func pextSlice(dst, src []uint64, mask uint64) { panic("pext") }
+func pdepSlice(dst, src []uint64, mask uint64) { panic("pdep") }
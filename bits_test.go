@@ -38,3 +38,87 @@ func TestBitCompress(t *testing.T) {
 		}
 	}
 }
+
+func slowExpand(mask uint64, value uint64) (r uint64) {
+	n := uint(0)
+	for i := uint(0); i < 64; i++ {
+		t := uint64(1) << i
+		if mask&t != 0 {
+			if value&(1<<n) != 0 {
+				r |= t
+			}
+			n++
+		}
+	}
+	return
+}
+
+func TestBitExpand(t *testing.T) {
+	var b BitCompressUint64
+	for i := 0; i < 100000; i++ {
+		mask := rand.Uint64()
+		value := rand.Uint64()
+		b.SetMask(mask)
+		if got, want := b.Expand(value), slowExpand(mask, value); got != want {
+			t.Fatalf("mask %x value %x: %x != %x", mask, value, got, want)
+		}
+		if got := b.Compress(b.Expand(value)); got != slowCompress(mask, slowExpand(mask, value)) {
+			t.Fatalf("mask %x value %x: compress expand %x", mask, value, got)
+		}
+	}
+}
+
+// Compare assembly and generic slice functions.
+func TestBitCompressSlice(t *testing.T) {
+	var b BitCompressUint64
+	src := make([]uint64, 1000)
+	dst := [2][]uint64{make([]uint64, len(src)), make([]uint64, len(src))}
+	bmi2 := bitCompressHaveBMI2
+	defer func() { bitCompressHaveBMI2 = bmi2 }()
+	for iter := 0; iter < 100; iter++ {
+		b.SetMask(rand.Uint64())
+		for i := range src {
+			src[i] = rand.Uint64()
+		}
+		// Check partial slices: only min(len(dst), len(src)) elements are written.
+		n := rand.Intn(len(src))
+		for i := range dst {
+			bitCompressHaveBMI2 = bmi2 && i == 0
+			for j := range dst[i] {
+				dst[i][j] = 0
+			}
+			b.CompressSlice(dst[i][:n], src)
+		}
+		for j := range src {
+			want := uint64(0)
+			if j < n {
+				want = slowCompress(b.Mask(), src[j])
+			}
+			if dst[0][j] != want || dst[1][j] != want {
+				t.Fatalf("compress %d: %x %x != %x", j, dst[0][j], dst[1][j], want)
+			}
+		}
+		for i := range dst {
+			bitCompressHaveBMI2 = bmi2 && i == 0
+			b.ExpandSlice(dst[i], src[:n])
+		}
+		for j := 0; j < n; j++ {
+			if want := slowExpand(b.Mask(), src[j]); dst[0][j] != want || dst[1][j] != want {
+				t.Fatalf("expand %d: %x %x != %x", j, dst[0][j], dst[1][j], want)
+			}
+		}
+	}
+}
+
+func BenchmarkBitCompressSlice(b *testing.B) {
+	var c BitCompressUint64
+	c.SetMask(0x0f0f_00ff_f0f0_1234)
+	x := make([]uint64, 1024)
+	for i := range x {
+		x[i] = rand.Uint64()
+	}
+	b.SetBytes(int64(8 * len(x)))
+	for i := 0; i < b.N; i++ {
+		c.CompressSlice(x, x)
+	}
+}
@@ -4,6 +4,15 @@
 
 package elib
 
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
 // Vector capacities of the form 2^i + 2^j
 type Cap uint32
 
@@ -71,3 +80,221 @@ func (c Cap) Next() Cap { return c.NextUnit(3, 2) }
 
 // NextResizeCap gives next larger resizeable array capacity.
 func NextResizeCap(x Index) Index { return Index(Cap(x).Next()) }
+
+// GrowthPolicy selects how capacity of vectors, pools and heaps grows when they are resized.
+// The zero policy is the default: capacities of form 2^i + 2^j growing by sqrt(2) for small vectors
+// down to 2^(1/16) for large ones.
+type GrowthPolicy struct {
+	// When non-zero capacity grows geometrically by given factor (e.g. 1.5 or 2).
+	// Factor must be greater than 1.
+	Factor float64
+
+	// Minimum capacity.
+	MinCap Index
+
+	// When non-zero capacity in bytes is rounded up to a multiple of 2^Log2RoundBytes
+	// (e.g. 12 for 4k pages).
+	Log2RoundBytes uint
+
+	// When non-zero hard maximum capacity: growing beyond MaxCap elements panics.
+	MaxCap Index
+}
+
+var (
+	// ErrGrowthMax is passed to panic when vector would grow beyond maximum capacity of its growth policy.
+	ErrGrowthMax = errors.New("growth: too large")
+	// ErrGrowthFactor is passed to panic when growth factor is non-zero but not greater than 1
+	// (capacity would grow by one element at a time making appends quadratic).
+	ErrGrowthFactor = errors.New("growth: factor must be greater than 1")
+)
+
+// NextCap gives new capacity for vector of given capacity and element size growing to at least newLen elements.
+func (p *GrowthPolicy) NextCap(oldCap, newLen Index, eltBytes uint) (c Index) {
+	if p.MaxCap != 0 && newLen > p.MaxCap {
+		panic(ErrGrowthMax)
+	}
+	if p.Factor != 0 && !(p.Factor > 1) {
+		panic(ErrGrowthFactor)
+	}
+	if p.Factor == 0 {
+		c = NextResizeCap(newLen)
+	} else if c = Index(float64(oldCap) * p.Factor); c < newLen {
+		c = newLen
+	}
+	if c < p.MinCap {
+		c = p.MinCap
+	}
+	if p.Log2RoundBytes != 0 && eltBytes != 0 {
+		u := uint64(1)<<p.Log2RoundBytes - 1
+		b := (uint64(c)*uint64(eltBytes) + u) &^ u
+		c = Index(b / uint64(eltBytes))
+	}
+	if p.MaxCap != 0 && c > p.MaxCap {
+		c = p.MaxCap
+	}
+	return
+}
+
+func (p *GrowthPolicy) String() (s string) {
+	if p.Factor == 0 {
+		s = "default"
+	} else {
+		s = fmt.Sprintf("x%g", p.Factor)
+	}
+	if p.MinCap != 0 {
+		s += fmt.Sprintf(" min %d", p.MinCap)
+	}
+	if p.Log2RoundBytes != 0 {
+		s += " round " + MemorySize(1<<p.Log2RoundBytes).String()
+	}
+	if p.MaxCap != 0 {
+		s += fmt.Sprintf(" max %d", p.MaxCap)
+	}
+	return
+}
+
+// GrowthStats accounts for memory allocated by resizes.
+// Statistics are cumulative: they only grow since vectors freed by garbage collector are not seen.
+type GrowthStats struct {
+	// Number of resizes.
+	Resizes uint64
+
+	// Total bytes allocated and copied by resizes.
+	BytesAllocated, BytesCopied uint64
+
+	// New minus old capacity in bytes summed over resizes.
+	// Equals capacity currently held only when no vector has been freed.
+	BytesGrown uint64
+
+	// Bytes of capacity beyond requested length at time of resize summed over resizes.
+	BytesWasted uint64
+}
+
+// Growth holds growth policy and statistics for a vector, pool or heap type.
+// Templates generate one Growth per type (e.g. WordVecGrowth for WordVec); generic
+// vectors have one Growth per element type (see VecGrowthOf).
+// Policy should be set before vectors of type are resized.
+type Growth struct {
+	Name     string
+	EltBytes uint
+	Policy   GrowthPolicy
+	stats    GrowthStats
+}
+
+var growths struct {
+	mu sync.Mutex
+	m  map[string]*Growth
+}
+
+func newGrowth(name string, elts ...interface{}) (g *Growth) {
+	g = &Growth{Name: name}
+	for _, e := range elts {
+		g.EltBytes += uint(reflect.TypeOf(e).Elem().Size())
+	}
+	return
+}
+
+// Register growth by name; false if name is already taken by another growth.
+func (g *Growth) register() (ok bool) {
+	growths.mu.Lock()
+	defer growths.mu.Unlock()
+	return g.registerLocked()
+}
+
+func (g *Growth) registerLocked() (ok bool) {
+	if growths.m == nil {
+		growths.m = make(map[string]*Growth)
+	}
+	if _, dup := growths.m[g.Name]; dup {
+		return false
+	}
+	growths.m[g.Name] = g
+	return true
+}
+
+// NewGrowth registers growth for named type.  Elements are given as nil pointers (e.g. (*Word)(nil));
+// element size is sum of sizes of pointed to types.
+// Names must be unique: NewGrowth panics if name is already registered.
+func NewGrowth(name string, elts ...interface{}) (g *Growth) {
+	g = newGrowth(name, elts...)
+	if !g.register() {
+		panic(fmt.Errorf("growth: duplicate name %s", name))
+	}
+	return
+}
+
+// GetGrowth finds growth by type name (e.g. "elib.WordVec").
+func GetGrowth(name string) (g *Growth, ok bool) {
+	growths.mu.Lock()
+	defer growths.mu.Unlock()
+	g, ok = growths.m[name]
+	return
+}
+
+// NextCap gives new capacity according to policy for vector with given length and capacity
+// growing to newLen elements.
+func (g *Growth) NextCap(oldLen, oldCap, newLen Index) (c Index) {
+	c = g.Policy.NextCap(oldCap, newLen, g.EltBytes)
+	s := &g.stats
+	b := uint64(g.EltBytes)
+	atomic.AddUint64(&s.Resizes, 1)
+	atomic.AddUint64(&s.BytesAllocated, uint64(c)*b)
+	atomic.AddUint64(&s.BytesCopied, uint64(oldLen)*b)
+	atomic.AddUint64(&s.BytesGrown, uint64(c-oldCap)*b)
+	atomic.AddUint64(&s.BytesWasted, uint64(c-newLen)*b)
+	return
+}
+
+func (g *Growth) Stats() (s GrowthStats) {
+	s.Resizes = atomic.LoadUint64(&g.stats.Resizes)
+	s.BytesAllocated = atomic.LoadUint64(&g.stats.BytesAllocated)
+	s.BytesCopied = atomic.LoadUint64(&g.stats.BytesCopied)
+	s.BytesGrown = atomic.LoadUint64(&g.stats.BytesGrown)
+	s.BytesWasted = atomic.LoadUint64(&g.stats.BytesWasted)
+	return
+}
+
+// Columns are totals over all resizes (see GrowthStats); in particular wasted is percent of
+// bytes allocated which was beyond requested length at time of resize, not current slack.
+type growthRow struct {
+	Name            string     `format:"%-40s" align:"left"`
+	Policy          string     `align:"left"`
+	Resizes         uint64     `align:"right"`
+	Total_Allocated MemorySize `align:"right"`
+	Total_Copied    MemorySize `align:"right"`
+	Total_Grown     MemorySize `align:"right"`
+	Total_Wasted    float64    `format:"%.1f%%" align:"right"`
+}
+
+// GrowthReport tabulates cumulative statistics of all types which have been resized sorted by
+// capacity grown.
+func GrowthReport() *table {
+	var rows []growthRow
+	growths.mu.Lock()
+	for _, g := range growths.m {
+		s := g.Stats()
+		if s.Resizes == 0 {
+			continue
+		}
+		r := growthRow{
+			Name:            g.Name,
+			Policy:          g.Policy.String(),
+			Resizes:         s.Resizes,
+			Total_Allocated: MemorySize(s.BytesAllocated),
+			Total_Copied:    MemorySize(s.BytesCopied),
+			Total_Grown:     MemorySize(s.BytesGrown),
+		}
+		if s.BytesAllocated != 0 {
+			r.Total_Wasted = 100 * float64(s.BytesWasted) / float64(s.BytesAllocated)
+		}
+		rows = append(rows, r)
+	}
+	growths.mu.Unlock()
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Total_Grown != rows[j].Total_Grown {
+			return rows[i].Total_Grown > rows[j].Total_Grown
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return Tabulate(rows)
+}
@@ -0,0 +1,104 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGrowthPolicy(t *testing.T) {
+	tests := []struct {
+		p              GrowthPolicy
+		oldCap, newLen Index
+		eltBytes       uint
+		want           Index
+	}{
+		{GrowthPolicy{}, 0, 5, 8, NextResizeCap(5)},
+		{GrowthPolicy{Factor: 2}, 16, 17, 8, 32},
+		{GrowthPolicy{Factor: 1.5}, 16, 30, 8, 30},
+		{GrowthPolicy{Factor: 2, MinCap: 64}, 0, 1, 8, 64},
+		{GrowthPolicy{Factor: 2, Log2RoundBytes: 12}, 16, 17, 24, 170},
+		{GrowthPolicy{Factor: 2, MaxCap: 100}, 64, 65, 8, 100},
+	}
+	for i, x := range tests {
+		if got := x.p.NextCap(x.oldCap, x.newLen, x.eltBytes); got != x.want {
+			t.Errorf("%d: %s: got %d want %d", i, &x.p, got, x.want)
+		}
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != ErrGrowthMax {
+				t.Errorf("expected ErrGrowthMax got %v", r)
+			}
+		}()
+		p := GrowthPolicy{MaxCap: 10}
+		p.NextCap(10, 11, 8)
+	}()
+
+	for _, f := range []float64{1, 0.5, -2} {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrGrowthFactor {
+					t.Errorf("factor %g: expected ErrGrowthFactor got %v", f, r)
+				}
+			}()
+			p := GrowthPolicy{Factor: f}
+			p.NextCap(10, 11, 8)
+		}()
+	}
+}
+
+func TestGrowthStats(t *testing.T) {
+	g := NewGrowth("elib.testGrowthVec", (*uint64)(nil))
+	defer func() {
+		growths.mu.Lock()
+		delete(growths.m, g.Name)
+		growths.mu.Unlock()
+	}()
+	g.Policy.Factor = 2
+	g.Policy.MinCap = 4
+	var v []uint64
+	for i := 0; i < 100; i++ {
+		if c := Index(cap(v)); Index(len(v)) >= c {
+			c = g.NextCap(Index(len(v)), c, Index(len(v))+1)
+			q := make([]uint64, len(v), c)
+			copy(q, v)
+			v = q
+		}
+		v = append(v, uint64(i))
+	}
+	s := g.Stats()
+	// Capacities 4, 8, 16, 32, 64, 128.
+	if s.Resizes != 6 || s.BytesGrown != 8*128 || s.BytesAllocated != 8*252 || s.BytesCopied != 8*124 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+	if s.BytesWasted != 8*(3+3+7+15+31+63) {
+		t.Errorf("wasted %d", s.BytesWasted)
+	}
+	if x, ok := GetGrowth(g.Name); !ok || x != g {
+		t.Errorf("GetGrowth %s failed", g.Name)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("duplicate growth name %s accepted", g.Name)
+			}
+		}()
+		NewGrowth(g.Name, (*uint32)(nil))
+	}()
+	if x, _ := GetGrowth(g.Name); x != g {
+		t.Errorf("duplicate growth replaced %s", g.Name)
+	}
+
+	var b bytes.Buffer
+	GrowthReport().Write(&b)
+	if !strings.Contains(b.String(), g.Name) || !strings.Contains(b.String(), "x2 min 4") ||
+		!strings.Contains(b.String(), "Total Grown") || !strings.Contains(b.String(), "48.4%") {
+		t.Errorf("report missing %s:\n%s", g.Name, b.String())
+	}
+}
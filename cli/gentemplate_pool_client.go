@@ -16,6 +16,9 @@ type clientPool struct {
 	clients []client
 }
 
+// Growth policy and statistics for clientPool.
+var clientPoolGrowth = elib.NewGrowth("cli.clientPool", (*client)(nil))
+
 func (p *clientPool) GetIndex() (i uint) {
 	l := uint(len(p.clients))
 	i = p.Pool.GetIndex(l)
@@ -41,7 +44,7 @@ func (p *clientPool) Resize(n uint) {
 	c := elib.Index(cap(p.clients))
 	l := elib.Index(len(p.clients) + int(n))
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = clientPoolGrowth.NextCap(elib.Index(len(p.clients)), c, l)
 		q := make([]client, l, c)
 		copy(q, p.clients)
 		p.clients = q
@@ -53,7 +56,7 @@ func (p *clientPool) Validate(i uint) {
 	c := elib.Index(cap(p.clients))
 	l := elib.Index(i) + 1
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = clientPoolGrowth.NextCap(elib.Index(len(p.clients)), c, l)
 		q := make([]client, l, c)
 		copy(q, p.clients)
 		p.clients = q
@@ -86,3 +89,45 @@ func (p *clientPool) ForeachIndex(f func(i uint)) {
 		}
 	}
 }
+
+// SetConcurrent puts pool in concurrent mode with fixed capacity of maxLen elements.
+// Data is allocated up front so that elements do not move.  Each thread allocates via its own cache
+// (see Pool.NewCache) and must only access elements it has allocated.
+func (p *clientPool) SetConcurrent(batch, maxLen uint) {
+	p.Pool.SetConcurrent(batch, maxLen)
+	p.Validate(maxLen - 1)
+}
+
+// GetHandle allocates new element returning its index and handle.
+func (p *clientPool) GetHandle() (i uint, h elib.PoolHandle) {
+	i = p.GetIndex()
+	h = p.Handle(i)
+	return
+}
+
+// ValidateHandle returns index for handle or error if handle is stale.
+func (p *clientPool) ValidateHandle(h elib.PoolHandle) (i uint, err error) {
+	if i = h.Index(); i >= uint(len(p.clients)) {
+		err = elib.ErrPoolStaleHandle
+		return
+	}
+	return p.Pool.ValidateHandle(h)
+}
+
+// PutHandle frees element for handle.  Stale handles are not freed.
+func (p *clientPool) PutHandle(h elib.PoolHandle) (err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		p.PutIndex(i)
+	}
+	return
+}
+
+// GetByHandle returns pointer to element for handle or error if handle is stale.
+func (p *clientPool) GetByHandle(h elib.PoolHandle) (x *client, err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		x = &p.clients[i]
+	}
+	return
+}
@@ -16,6 +16,9 @@ type FilePool struct {
 	Files []File
 }
 
+// Growth policy and statistics for FilePool.
+var FilePoolGrowth = elib.NewGrowth("cli.FilePool", (*File)(nil))
+
 func (p *FilePool) GetIndex() (i uint) {
 	l := uint(len(p.Files))
 	i = p.Pool.GetIndex(l)
@@ -41,7 +44,7 @@ func (p *FilePool) Resize(n uint) {
 	c := elib.Index(cap(p.Files))
 	l := elib.Index(len(p.Files) + int(n))
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = FilePoolGrowth.NextCap(elib.Index(len(p.Files)), c, l)
 		q := make([]File, l, c)
 		copy(q, p.Files)
 		p.Files = q
@@ -53,7 +56,7 @@ func (p *FilePool) Validate(i uint) {
 	c := elib.Index(cap(p.Files))
 	l := elib.Index(i) + 1
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = FilePoolGrowth.NextCap(elib.Index(len(p.Files)), c, l)
 		q := make([]File, l, c)
 		copy(q, p.Files)
 		p.Files = q
@@ -86,3 +89,45 @@ func (p *FilePool) ForeachIndex(f func(i uint)) {
 		}
 	}
 }
+
+// SetConcurrent puts pool in concurrent mode with fixed capacity of maxLen elements.
+// Data is allocated up front so that elements do not move.  Each thread allocates via its own cache
+// (see Pool.NewCache) and must only access elements it has allocated.
+func (p *FilePool) SetConcurrent(batch, maxLen uint) {
+	p.Pool.SetConcurrent(batch, maxLen)
+	p.Validate(maxLen - 1)
+}
+
+// GetHandle allocates new element returning its index and handle.
+func (p *FilePool) GetHandle() (i uint, h elib.PoolHandle) {
+	i = p.GetIndex()
+	h = p.Handle(i)
+	return
+}
+
+// ValidateHandle returns index for handle or error if handle is stale.
+func (p *FilePool) ValidateHandle(h elib.PoolHandle) (i uint, err error) {
+	if i = h.Index(); i >= uint(len(p.Files)) {
+		err = elib.ErrPoolStaleHandle
+		return
+	}
+	return p.Pool.ValidateHandle(h)
+}
+
+// PutHandle frees element for handle.  Stale handles are not freed.
+func (p *FilePool) PutHandle(h elib.PoolHandle) (err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		p.PutIndex(i)
+	}
+	return
+}
+
+// GetByHandle returns pointer to element for handle or error if handle is stale.
+func (p *FilePool) GetByHandle(h elib.PoolHandle) (x *File, err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		x = &p.Files[i]
+	}
+	return
+}
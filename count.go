@@ -5,26 +5,122 @@
 package elib
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 )
 
 // Count implements the Value interface so flags can be specified as
-// either integer (1000000) or sometimes more conveniently as floating point (1e6).
+// either integer (1000000) or sometimes more conveniently as floating point (1e6)
+// or with SI (1M) or binary (1Mi) unit suffix.
 type Count int
 
 func (t *Count) Set(s string) (err error) {
-	v, err := strconv.ParseInt(s, 0, 64)
+	v, err := ParseCount(s)
+	if err == nil {
+		*t = v
+	}
+	return
+}
+
+func (t *Count) String() string { return fmt.Sprintf("%v", *t) }
+
+var ErrUnitsSyntax = errors.New("invalid number or unit suffix")
+
+const unitPrefixes = "kmgtpe"
+
+// Splits number from unit suffix: k, M, G, T, P, E (upper or lower case) optionally followed by i for
+// powers of 1024 instead of 1000.  When memory is set units are always powers of 1024 (as printed by
+// MemorySize.String) and may be followed by B (e.g. 64k, 2MiB, 1.5GB).
+func splitUnits(s string, memory bool) (num string, unit uint64, err error) {
+	num, unit = s, 1
+	l := strings.ToLower(s)
+	if strings.HasPrefix(l, "0x") || strings.HasPrefix(l, "-0x") {
+		return
+	}
+	i := len(l)
+	for i > 0 && l[i-1] >= 'a' && l[i-1] <= 'z' {
+		i--
+	}
+	num, suffix := s[:i], l[i:]
+	if memory {
+		suffix = strings.TrimSuffix(suffix, "b")
+	}
+	if suffix == "" {
+		return
+	}
+	p := strings.IndexByte(unitPrefixes, suffix[0])
+	if p < 0 {
+		err = ErrUnitsSyntax
+		return
+	}
+	base := uint64(1000)
+	switch suffix[1:] {
+	case "i":
+		base = 1024
+	case "":
+		if memory {
+			base = 1024
+		}
+	default:
+		err = ErrUnitsSyntax
+		return
+	}
+	for ; p >= 0; p-- {
+		unit *= base
+	}
+	return
+}
+
+// Parse number with units returning value and sign.  Values which overflow 64 bits are errors.
+// Fractional values are rounded to nearest integer.
+func parseUnits(s string, memory bool) (v uint64, negative bool, err error) {
+	num, unit, err := splitUnits(s, memory)
 	if err != nil {
-		v, e := strconv.ParseFloat(s, 64)
-		*t = Count(v)
-		if e == nil {
-			err = nil
+		return
+	}
+	if negative = strings.HasPrefix(num, "-"); negative || strings.HasPrefix(num, "+") {
+		num = num[1:]
+	}
+	if strings.HasPrefix(num, "+") || strings.HasPrefix(num, "-") {
+		err = ErrUnitsSyntax
+		return
+	}
+	if x, e := strconv.ParseUint(num, 0, 64); e == nil {
+		if x != 0 && unit > math.MaxUint64/x {
+			err = ErrUnitsSyntax
 			return
 		}
+		v = x * unit
+		return
+	}
+	f, e := strconv.ParseFloat(num, 64)
+	if e != nil || f < 0 {
+		err = ErrUnitsSyntax
+		return
+	}
+	if f = math.Round(f * float64(unit)); f >= 1<<64 {
+		err = ErrUnitsSyntax
+		return
 	}
-	*t = Count(v)
+	v = uint64(f)
 	return
 }
 
-func (t *Count) String() string { return fmt.Sprintf("%v", *t) }
+// ParseCount parses integer (0x10), floating point (1e6) or number with unit suffix (1.5M, 64Ki).
+func ParseCount(s string) (c Count, err error) {
+	v, neg, err := parseUnits(s, false)
+	if err != nil {
+		return
+	}
+	if v > math.MaxInt64 || Count(v) < 0 || uint64(Count(v)) != v {
+		err = ErrUnitsSyntax
+		return
+	}
+	if c = Count(v); neg {
+		c = -c
+	}
+	return
+}
@@ -0,0 +1,63 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestParseUnits(t *testing.T) {
+	for _, c := range []struct {
+		s     string
+		count Count
+		mem   MemorySize
+		err   bool
+	}{
+		{s: "123", count: 123, mem: 123},
+		{s: "0x10", count: 16, mem: 16},
+		{s: "10e6", count: 10e6, mem: 10e6},
+		{s: "64k", count: 64000, mem: 64 << 10},
+		{s: "64Ki", count: 64 << 10, mem: 64 << 10},
+		{s: "2MiB", err: true, mem: 2 << 20},
+		{s: "2MB", err: true, mem: 2 << 20},
+		{s: "1.5G", count: 1500000000, mem: 3 << 29},
+		{s: "1e3k", count: 1e6, mem: 1000 << 10},
+		{s: "-5", count: -5, err: true},
+		{s: "+5", count: 5, mem: 5},
+		{s: "+-5", err: true},
+		{s: "1.005k", count: 1005, mem: 1029},
+		{s: "1x", err: true},
+		{s: "k", err: true},
+		{s: "20E", err: true},
+	} {
+		count, err := ParseCount(c.s)
+		if countErr := c.err && c.count == 0; (err != nil) != countErr || count != c.count {
+			t.Errorf("count %s: %d %v", c.s, count, err)
+		}
+		mem, err := ParseMemorySize(c.s)
+		if memErr := c.err && c.mem == 0; (err != nil) != memErr || mem != c.mem {
+			t.Errorf("memory size %s: %d %v", c.s, mem, err)
+		}
+	}
+
+	// Round trip of MemorySize.String.
+	for _, m := range []MemorySize{0, 1000, 4 << 10, 3 << 29, 5 << 40} {
+		if x, err := ParseMemorySize(m.String()); err != nil || x != m {
+			t.Errorf("%d: %s parsed as %d %v", m, m, x, err)
+		}
+	}
+
+	var (
+		fs    flag.FlagSet
+		count Count
+		mem   MemorySize
+	)
+	fs.Var(&count, "count", "")
+	fs.Var(&mem, "size", "")
+	if err := fs.Parse([]string{"-count", "2k", "-size", "16M"}); err != nil || count != 2000 || mem != 16<<20 {
+		t.Errorf("flags: %d %d %v", count, mem, err)
+	}
+}
@@ -13,11 +13,14 @@ import (
 
 type EventVec []Event
 
+// Growth policy and statistics for EventVec.
+var EventVecGrowth = elib.NewGrowth("elog.EventVec", (*Event)(nil))
+
 func (p *EventVec) Resize(n uint) {
 	c := elib.Index(cap(*p))
 	l := elib.Index(len(*p)) + elib.Index(n)
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = EventVecGrowth.NextCap(elib.Index(len(*p)), c, l)
 		q := make([]Event, l, c)
 		copy(q, *p)
 		*p = q
@@ -42,7 +45,7 @@ func (p *EventVec) validate(new_len uint, zero *Event) *Event {
 func (p *EventVec) validateSlowPath(zero *Event,
 	c, l, lʹ elib.Index) *Event {
 	if l > c {
-		cNext := elib.NextResizeCap(l)
+		cNext := EventVecGrowth.NextCap(lʹ, c, l)
 		q := make([]Event, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
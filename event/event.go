@@ -31,6 +31,17 @@ func (p *timedEventPool) Compare(i, j int) int {
 type Pool struct {
 	pool    timedEventPool
 	fibheap elib.FibHeap
+
+	// Non-nil when pool uses timing wheel instead of fibheap.
+	wheel *timingWheel
+}
+
+// UseTimingWheel orders events with a hierarchical timing wheel instead of a fibonacci heap.
+// Wheel ticks are 2^log2Resolution cpu.Time units.  Must be called before events are added.
+// Wheels are well suited to large numbers of timers most of which are deleted before they expire.
+func (p *Pool) UseTimingWheel(log2Resolution uint) {
+	p.wheel = &timingWheel{}
+	p.wheel.init(log2Resolution)
 }
 
 func (p *Pool) Elts() uint                   { return p.pool.Elts() }
@@ -39,16 +50,41 @@ func (p *Pool) Foreach(f func(e TimedActor)) { p.pool.Foreach(f) }
 func (p *Pool) Add(e TimedActor) (ei uint) {
 	ei = p.pool.GetIndex()
 	p.pool.events[ei] = e
-	p.fibheap.Add(ei)
+	if p.wheel != nil {
+		p.wheel.add(ei, e.EventTime())
+	} else {
+		p.fibheap.Add(ei)
+	}
 	return ei
 }
 
 func (p *Pool) Del(ei uint) {
-	p.fibheap.Del(ei)
+	if p.wheel != nil {
+		p.wheel.del(ei, p.pool.events[ei].EventTime())
+	} else {
+		p.fibheap.Del(ei)
+	}
+	p.pool.events[ei] = nil
 	p.pool.PutIndex(ei)
 }
 
+// Remove expired event from pool and either perform its action or add it to given vector.
+func (p *Pool) expire(ei uint, iv *ActorVec) {
+	e := p.pool.events[ei]
+	p.pool.events[ei] = nil
+	p.pool.PutIndex(ei)
+	if iv != nil {
+		*iv = append(*iv, e)
+	} else {
+		e.EventAction()
+	}
+}
+
 func (p *Pool) advance(t cpu.Time, iv *ActorVec) {
+	if p.wheel != nil {
+		p.wheel.advance(t, &p.pool, func(ei uint) { p.expire(ei, iv) })
+		return
+	}
 	for {
 		ei, valid := p.fibheap.Min(&p.pool)
 		if !valid {
@@ -59,15 +95,14 @@ func (p *Pool) advance(t cpu.Time, iv *ActorVec) {
 			break
 		}
 		p.fibheap.Del(ei)
-		if iv != nil {
-			*iv = append(*iv, e)
-		} else {
-			e.EventAction()
-		}
+		p.expire(ei, iv)
 	}
 }
 
 func (p *Pool) NextTime() (t cpu.Time, valid bool) {
+	if p.wheel != nil {
+		return p.wheel.nextTime(&p.pool)
+	}
 	ei, valid := p.fibheap.Min(&p.pool)
 	if valid {
 		t = p.pool.events[ei].EventTime()
@@ -0,0 +1,111 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"github.com/platinasystems/elib/cpu"
+
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+type testTimer struct {
+	t     cpu.Time
+	fired *int
+}
+
+func (e *testTimer) EventAction()        { *e.fired++ }
+func (e *testTimer) EventTime() cpu.Time { return e.t }
+func (e *testTimer) String() string      { return fmt.Sprintf("timer %d", e.t) }
+
+func TestTimingWheel(t *testing.T) {
+	var heap, wheel Pool
+	wheel.UseTimingWheel(4)
+	var heapIndex, wheelIndex []uint
+	var times []cpu.Time
+	var heapFired, wheelFired int
+	r := rand.New(rand.NewSource(1))
+	now := cpu.Time(1 << 40)
+	for iter := 0; iter < 100000; iter++ {
+		switch x := r.Intn(10); {
+		case x < 5:
+			// Delays from a few ticks to many wheel levels.
+			et := now + cpu.Time(r.Int63n(1<<uint(4+r.Intn(32))))
+			heapIndex = append(heapIndex, heap.Add(&testTimer{t: et, fired: &heapFired}))
+			wheelIndex = append(wheelIndex, wheel.Add(&testTimer{t: et, fired: &wheelFired}))
+			times = append(times, et)
+		case x < 8 && len(times) > 0:
+			i := r.Intn(len(times))
+			heap.Del(heapIndex[i])
+			wheel.Del(wheelIndex[i])
+			l := len(times) - 1
+			heapIndex[i], wheelIndex[i], times[i] = heapIndex[l], wheelIndex[l], times[l]
+			heapIndex, wheelIndex, times = heapIndex[:l], wheelIndex[:l], times[:l]
+		default:
+			now += cpu.Time(r.Int63n(1 << uint(r.Intn(24))))
+			heap.Advance(now)
+			wheel.Advance(now)
+			// Drop expired timers.
+			for i := 0; i < len(times); {
+				if times[i] <= now {
+					l := len(times) - 1
+					heapIndex[i], wheelIndex[i], times[i] = heapIndex[l], wheelIndex[l], times[l]
+					heapIndex, wheelIndex, times = heapIndex[:l], wheelIndex[:l], times[:l]
+				} else {
+					i++
+				}
+			}
+		}
+		ht, hok := heap.NextTime()
+		wt, wok := wheel.NextTime()
+		if hok != wok || ht != wt || heapFired != wheelFired || heap.Elts() != wheel.Elts() {
+			t.Fatalf("iter %d: next %v %d != %v %d, fired %d != %d, elts %d != %d",
+				iter, hok, ht, wok, wt, heapFired, wheelFired, heap.Elts(), wheel.Elts())
+		}
+		if heap.Elts() != uint(len(times)) {
+			t.Fatalf("iter %d: elts %d != %d", iter, heap.Elts(), len(times))
+		}
+	}
+}
+
+// Timers which are mostly deleted before they expire (e.g. TCP retransmit timers).
+func benchmarkPool(b *testing.B, p *Pool) {
+	const nTimers = 1 << 16
+	var fired int
+	r := rand.New(rand.NewSource(1))
+	timers := make([]testTimer, nTimers)
+	index := make([]uint, nTimers)
+	now := cpu.Time(0)
+	for i := range timers {
+		timers[i] = testTimer{t: now + cpu.Time(r.Int63n(1<<20)), fired: &fired}
+		index[i] = p.Add(&timers[i])
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		j := i % nTimers
+		if timers[j].t > now {
+			p.Del(index[j])
+		}
+		if i%16 == 0 {
+			now += 1 << 8
+			p.Advance(now)
+		}
+		timers[j].t = now + cpu.Time(r.Int63n(1<<20))
+		index[j] = p.Add(&timers[j])
+		p.NextTime()
+	}
+}
+
+func BenchmarkPoolFibHeap(b *testing.B) {
+	var p Pool
+	benchmarkPool(b, &p)
+}
+
+func BenchmarkPoolTimingWheel(b *testing.B) {
+	var p Pool
+	p.UseTimingWheel(4)
+	benchmarkPool(b, &p)
+}
@@ -16,6 +16,9 @@ type timedEventPool struct {
 	events []TimedActor
 }
 
+// Growth policy and statistics for timedEventPool.
+var timedEventPoolGrowth = elib.NewGrowth("event.timedEventPool", (*TimedActor)(nil))
+
 func (p *timedEventPool) GetIndex() (i uint) {
 	l := uint(len(p.events))
 	i = p.Pool.GetIndex(l)
@@ -41,7 +44,7 @@ func (p *timedEventPool) Resize(n uint) {
 	c := elib.Index(cap(p.events))
 	l := elib.Index(len(p.events) + int(n))
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = timedEventPoolGrowth.NextCap(elib.Index(len(p.events)), c, l)
 		q := make([]TimedActor, l, c)
 		copy(q, p.events)
 		p.events = q
@@ -53,7 +56,7 @@ func (p *timedEventPool) Validate(i uint) {
 	c := elib.Index(cap(p.events))
 	l := elib.Index(i) + 1
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = timedEventPoolGrowth.NextCap(elib.Index(len(p.events)), c, l)
 		q := make([]TimedActor, l, c)
 		copy(q, p.events)
 		p.events = q
@@ -86,3 +89,45 @@ func (p *timedEventPool) ForeachIndex(f func(i uint)) {
 		}
 	}
 }
+
+// SetConcurrent puts pool in concurrent mode with fixed capacity of maxLen elements.
+// Data is allocated up front so that elements do not move.  Each thread allocates via its own cache
+// (see Pool.NewCache) and must only access elements it has allocated.
+func (p *timedEventPool) SetConcurrent(batch, maxLen uint) {
+	p.Pool.SetConcurrent(batch, maxLen)
+	p.Validate(maxLen - 1)
+}
+
+// GetHandle allocates new element returning its index and handle.
+func (p *timedEventPool) GetHandle() (i uint, h elib.PoolHandle) {
+	i = p.GetIndex()
+	h = p.Handle(i)
+	return
+}
+
+// ValidateHandle returns index for handle or error if handle is stale.
+func (p *timedEventPool) ValidateHandle(h elib.PoolHandle) (i uint, err error) {
+	if i = h.Index(); i >= uint(len(p.events)) {
+		err = elib.ErrPoolStaleHandle
+		return
+	}
+	return p.Pool.ValidateHandle(h)
+}
+
+// PutHandle frees element for handle.  Stale handles are not freed.
+func (p *timedEventPool) PutHandle(h elib.PoolHandle) (err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		p.PutIndex(i)
+	}
+	return
+}
+
+// GetByHandle returns pointer to element for handle or error if handle is stale.
+func (p *timedEventPool) GetByHandle(h elib.PoolHandle) (x *TimedActor, err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		x = &p.events[i]
+	}
+	return
+}
@@ -13,11 +13,14 @@ import (
 
 type ActorVec []Actor
 
+// Growth policy and statistics for ActorVec.
+var ActorVecGrowth = elib.NewGrowth("event.ActorVec", (*Actor)(nil))
+
 func (p *ActorVec) Resize(n uint) {
 	c := elib.Index(cap(*p))
 	l := elib.Index(len(*p)) + elib.Index(n)
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = ActorVecGrowth.NextCap(elib.Index(len(*p)), c, l)
 		q := make([]Actor, l, c)
 		copy(q, *p)
 		*p = q
@@ -42,7 +45,7 @@ func (p *ActorVec) validate(new_len uint, zero *Actor) *Actor {
 func (p *ActorVec) validateSlowPath(zero *Actor,
 	c, l, lʹ elib.Index) *Actor {
 	if l > c {
-		cNext := elib.NextResizeCap(l)
+		cNext := ActorVecGrowth.NextCap(lʹ, c, l)
 		q := make([]Actor, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
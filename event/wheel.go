@@ -0,0 +1,213 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"github.com/platinasystems/elib"
+	"github.com/platinasystems/elib/cpu"
+
+	"math/bits"
+)
+
+// Hierarchical timing wheel: each level has 256 slots with each slot of level l covering 256^l ticks.
+// Events are placed at the level of the most significant byte in which their tick differs from
+// current tick and are moved (cascaded) to lower levels as time advances.  Add and Del are O(1).
+const (
+	log2WheelSlots = 8
+	nWheelSlots    = 1 << log2WheelSlots
+	wheelSlotMask  = nWheelSlots - 1
+)
+
+type wheelNode struct {
+	// Links to doubly linked list of events in slot; MaxIndex terminates list.
+	next, prev elib.Index
+
+	// Slot holding event: level*nWheelSlots + slot.
+	slot uint16
+}
+
+type timingWheel struct {
+	// Ticks are event times with low log2Resolution bits ignored.
+	log2Resolution uint
+
+	// Current tick; all events with earlier ticks have expired.
+	now uint64
+
+	nodes []wheelNode
+
+	// Index of first event in each slot or MaxIndex if slot is empty.
+	heads []elib.Index
+
+	// Bitmap of non-empty slots for each level.
+	occupied [][nWheelSlots / 64]uint64
+
+	// Cached time of next event.
+	next      cpu.Time
+	nextValid bool
+}
+
+func (w *timingWheel) init(log2Resolution uint) {
+	w.log2Resolution = log2Resolution
+	nLevels := (64 - log2Resolution + log2WheelSlots - 1) / log2WheelSlots
+	w.heads = make([]elib.Index, nLevels*nWheelSlots)
+	for i := range w.heads {
+		w.heads[i] = elib.MaxIndex
+	}
+	w.occupied = make([][nWheelSlots / 64]uint64, nLevels)
+}
+
+func (w *timingWheel) tick(t cpu.Time) uint64 { return uint64(t) >> w.log2Resolution }
+
+func (w *timingWheel) link(ei elib.Index, s uint) {
+	for elib.Index(len(w.nodes)) <= ei {
+		w.nodes = append(w.nodes, wheelNode{})
+	}
+	x := &w.nodes[ei]
+	h := w.heads[s]
+	x.slot, x.prev, x.next = uint16(s), elib.MaxIndex, h
+	if h != elib.MaxIndex {
+		w.nodes[h].prev = ei
+	} else {
+		w.occupied[s/nWheelSlots][s%nWheelSlots/64] |= 1 << (s % 64)
+	}
+	w.heads[s] = ei
+}
+
+func (w *timingWheel) unlink(ei elib.Index) {
+	x := &w.nodes[ei]
+	if x.prev != elib.MaxIndex {
+		w.nodes[x.prev].next = x.next
+	} else {
+		s := uint(x.slot)
+		w.heads[s] = x.next
+		if x.next == elib.MaxIndex {
+			w.occupied[s/nWheelSlots][s%nWheelSlots/64] &^= 1 << (s % 64)
+		}
+	}
+	if x.next != elib.MaxIndex {
+		w.nodes[x.next].prev = x.prev
+	}
+}
+
+func (w *timingWheel) insert(ei uint, t cpu.Time) {
+	tick := w.tick(t)
+	// Events in the past expire with current tick.
+	if tick < w.now {
+		tick = w.now
+	}
+	l := uint(0)
+	if d := tick ^ w.now; d != 0 {
+		l = uint(bits.Len64(d)-1) / log2WheelSlots
+	}
+	w.link(elib.Index(ei), l*nWheelSlots+uint(tick>>(l*log2WheelSlots))&wheelSlotMask)
+}
+
+func (w *timingWheel) add(ei uint, t cpu.Time) {
+	w.insert(ei, t)
+	if w.nextValid && t < w.next {
+		w.next = t
+	}
+}
+
+func (w *timingWheel) del(ei uint, t cpu.Time) {
+	w.unlink(elib.Index(ei))
+	if w.nextValid && t <= w.next {
+		w.nextValid = false
+	}
+}
+
+// First non-empty slot at or after current tick.  Slot at lowest level contains earliest events.
+func (w *timingWheel) nextSlot() (l, s uint, ok bool) {
+	for l = 0; l < uint(len(w.occupied)); l++ {
+		o := &w.occupied[l]
+		b := uint(w.now>>(l*log2WheelSlots)) & wheelSlotMask
+		for i := b / 64; i < uint(len(o)); i++ {
+			m := o[i]
+			if i == b/64 {
+				m &^= 1<<(b%64) - 1
+			}
+			if m != 0 {
+				s, ok = i*64+uint(bits.TrailingZeros64(m)), true
+				return
+			}
+		}
+	}
+	return
+}
+
+// First tick covered by given slot.
+func (w *timingWheel) slotStart(l, s uint) uint64 {
+	return w.now&^(1<<((l+1)*log2WheelSlots)-1) | uint64(s)<<(l*log2WheelSlots)
+}
+
+// Re-insert events of slot relative to current tick.
+func (w *timingWheel) cascade(s uint, p *timedEventPool) {
+	ei := w.heads[s]
+	w.heads[s] = elib.MaxIndex
+	w.occupied[s/nWheelSlots][s%nWheelSlots/64] &^= 1 << (s % 64)
+	for ei != elib.MaxIndex {
+		next := w.nodes[ei].next
+		w.insert(uint(ei), p.events[ei].EventTime())
+		ei = next
+	}
+}
+
+// Expire events of current tick with time at or before t.
+func (w *timingWheel) expire(t cpu.Time, p *timedEventPool, f func(ei uint)) {
+	s := w.now & wheelSlotMask
+	for ei := w.heads[s]; ei != elib.MaxIndex; {
+		if p.events[ei].EventTime() > t {
+			ei = w.nodes[ei].next
+			continue
+		}
+		w.unlink(ei)
+		f(uint(ei))
+		// Event action may add or delete events: rescan slot.
+		ei = w.heads[s]
+	}
+}
+
+func (w *timingWheel) advance(t cpu.Time, p *timedEventPool, f func(ei uint)) {
+	target := w.tick(t)
+	w.nextValid = false
+	for {
+		w.expire(t, p, f)
+		if w.now >= target {
+			return
+		}
+		l, s, ok := w.nextSlot()
+		if !ok {
+			w.now = target
+			return
+		}
+		n := w.slotStart(l, s)
+		if n > target {
+			// No events before target tick.
+			w.now = target
+			return
+		}
+		w.now = n
+		if l > 0 {
+			w.cascade(l*nWheelSlots+s, p)
+		}
+	}
+}
+
+func (w *timingWheel) nextTime(p *timedEventPool) (t cpu.Time, valid bool) {
+	if w.nextValid {
+		return w.next, true
+	}
+	l, s, ok := w.nextSlot()
+	if !ok {
+		return
+	}
+	for ei := w.heads[l*nWheelSlots+s]; ei != elib.MaxIndex; ei = w.nodes[ei].next {
+		if et := p.events[ei].EventTime(); !valid || et < t {
+			t, valid = et, true
+		}
+	}
+	w.next, w.nextValid = t, valid
+	return
+}
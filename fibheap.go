@@ -46,6 +46,9 @@ type FibHeap struct {
 	// Cached min index in heap.
 	minIndex Index
 	minValid bool
+
+	// Number of indices in heap.
+	len uint
 }
 
 func (f *FibHeap) node(ni Index) *fibNode {
@@ -78,14 +81,19 @@ func (f *FibHeap) unlink(xi Index) {
 	n.prev = x.prev
 }
 
+func (f *FibHeap) initRoot() {
+	f.root.next, f.root.prev = fibRootIndex, fibRootIndex
+	f.root.sup = MaxIndex
+	f.root.sub = MaxIndex
+}
+
 // Add a new index to heap.
 func (f *FibHeap) Add(xi uint) {
 	if len(f.nodes) == 0 {
-		f.root.next, f.root.prev = fibRootIndex, fibRootIndex
-		f.root.sup = MaxIndex
-		f.root.sub = MaxIndex
+		f.initRoot()
 	}
 	f.minValid = false
+	f.len++
 	f.nodes.Validate(uint(xi))
 	x := &f.nodes[xi]
 	x.sup = MaxIndex
@@ -119,16 +127,18 @@ func (f *FibHeap) Del(i uint) {
 	xi := Index(i)
 	f.unlink(xi)
 	f.cutChildren(xi)
+	f.len--
 
 	x := &f.nodes[xi]
-	supi := x.sup
 	f.minValid = f.minValid && xi != f.minIndex
-	if supi == MaxIndex {
-		return
+	if x.sup != MaxIndex {
+		f.cascadingCut(x.sup, x.next)
 	}
+}
 
-	// Adjust parent for deletion of child.
-	ni := x.next
+// Adjust parent for removal of child whose next sibling is ni.
+// Marked ancestors are cut and made roots.
+func (f *FibHeap) cascadingCut(supi, ni Index) {
 	for {
 		sup := &f.nodes[supi]
 		sup.nSub -= 1
@@ -139,12 +149,18 @@ func (f *FibHeap) Del(i uint) {
 			sup.sub = MaxIndex
 		}
 		sup2i := sup.sup
-		if !wasMarked || sup2i == MaxIndex {
+		if sup2i == MaxIndex {
+			// Roots are never marked.
+			sup.isMarked = false
+			break
+		}
+		if !wasMarked {
 			break
 		}
 		ni = sup.next
 		f.unlink(supi)
 		sup.sup = MaxIndex
+		sup.isMarked = false
 		f.addRoot(supi)
 		supi = sup2i
 	}
@@ -156,6 +172,36 @@ func (f *FibHeap) Update(xi uint) {
 	f.Add(xi)
 }
 
+// DecreaseKey updates heap after key of index xi has been lowered.
+// Node is cut from its parent (along with its children) when heap order is violated.
+// Amortized O(1).
+func (f *FibHeap) DecreaseKey(xi uint, data Ordered) {
+	i := Index(xi)
+	x := &f.nodes[i]
+	if supi := x.sup; supi != MaxIndex && data.Compare(int(i), int(supi)) < 0 {
+		ni := x.next
+		f.unlink(i)
+		x.sup = MaxIndex
+		x.isMarked = false
+		f.addRoot(i)
+		f.cascadingCut(supi, ni)
+	}
+	if f.minValid && x.sup == MaxIndex && data.Compare(int(i), int(f.minIndex)) < 0 {
+		f.minIndex = i
+	}
+}
+
+// PopMin deletes and returns index with minimum key.
+func (f *FibHeap) PopMin(data Ordered) (i uint, valid bool) {
+	if i, valid = f.Min(data); valid {
+		f.Del(i)
+	}
+	return
+}
+
+// Len returns number of indices in heap.
+func (f *FibHeap) Len() uint { return f.len }
+
 func (f *FibHeap) Min(data Ordered) (minu uint, valid bool) {
 	minu = uint(f.minIndex)
 	valid = f.minValid
@@ -242,8 +288,12 @@ func (n *fibNode) reloc(i int) {
 	}
 }
 
+// Merge adds all nodes of heap g to f.  Indices of g are offset by number of nodes in f.
 func (f *FibHeap) Merge(g *FibHeap) {
 	l := len(f.nodes)
+	if l == 0 {
+		f.initRoot()
+	}
 	f.nodes.Resize(uint(len(g.nodes)))
 	copy(f.nodes[l:], g.nodes)
 	for i := l; i < len(f.nodes); i++ {
@@ -252,12 +302,14 @@ func (f *FibHeap) Merge(g *FibHeap) {
 	r := g.root
 	r.reloc(l)
 	for ri := r.next; ri != fibRootIndex; {
-		r := &f.nodes[ri]
-		f.Add(uint(ri))
-		ri = r.next
+		ni := f.nodes[ri].next
+		f.addRoot(ri)
+		ri = ni
 	}
+	f.minValid = false
+	f.len += g.len
 }
 
 func (f *FibHeap) String() string {
-	return fmt.Sprintf("%d elts", len(f.nodes))
+	return fmt.Sprintf("%d elts", f.len)
 }
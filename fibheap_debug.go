@@ -164,6 +164,16 @@ func runFibHeapTest(t *testFibHeap) (err error) {
 			err = fmt.Errorf("iter %d: min %d != %d", iter, omin, fmin)
 			return
 		}
+		n := uint(0)
+		for i := range objs {
+			if objs[i] != 0 {
+				n++
+			}
+		}
+		if l := f.Len(); l != n {
+			err = fmt.Errorf("iter %d: len %d != %d", iter, l, n)
+			return
+		}
 
 		if t.validateEvery != 0 && iter%int(t.validateEvery) == 0 {
 			if err = f.validate(); err != nil {
@@ -195,9 +205,12 @@ func runFibHeapTest(t *testFibHeap) (err error) {
 			objs[x] = 1 + rand.Int63()
 			f.Add(x)
 		} else {
-			if rand.Int()%10 < 5 {
+			if r := rand.Int() % 10; r < 3 {
 				objs[x] = 1 + rand.Int63()
 				f.Update(x)
+			} else if r < 6 {
+				objs[x] = 1 + rand.Int63n(objs[x])
+				f.DecreaseKey(x, objs)
 			} else {
 				objs[x] = 0
 				f.Del(x)
@@ -211,11 +224,13 @@ func runFibHeapTest(t *testFibHeap) (err error) {
 	if t.verbose != 0 {
 		fmt.Printf("%d iterations: %+v\n", iter, f)
 	}
-	for i := range objs {
-		if objs[i] != 0 {
-			f.Del(uint(i))
-			objs[i] = 0
+	for last := int64(0); f.Len() > 0; {
+		i, _ := f.PopMin(objs)
+		if objs[i] < last {
+			err = fmt.Errorf("iter %d: pop min %d < %d", iter, objs[i], last)
+			return
 		}
+		last, objs[i] = objs[i], 0
 		err = validate()
 		if err != nil {
 			return
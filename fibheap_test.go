@@ -0,0 +1,21 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+import (
+	"testing"
+)
+
+func TestFibHeap(t *testing.T) {
+	c := testFibHeap{
+		iterations:    10000,
+		nObjects:      100,
+		validateEvery: 1,
+	}
+	err := runFibHeapTest(&c)
+	if err != nil {
+		t.Error(err)
+	}
+}
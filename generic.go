@@ -0,0 +1,344 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build go1.18
+
+package elib
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Generic equivalents of vec.tmpl, pool.tmpl and heap.tmpl.
+// (TypedPool is taken by the pool of mixed types, so generic pool is PoolOf.)
+
+// Vec is a growable vector with same semantics as vec.tmpl generated vectors.
+type Vec[T any] []T
+
+// Growths of generic vectors keyed by element type.
+var vecGrowths sync.Map // reflect.Type => *Growth
+
+// VecGrowthOf gives growth policy and statistics for Vec[T] (and so for PoolOf[T] and TypedHeap[T]).
+func VecGrowthOf[T any]() *Growth {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if g, ok := vecGrowths.Load(t); ok {
+		return g.(*Growth)
+	}
+	// Slow path under registry lock so that growth is named and registered exactly once.
+	growths.mu.Lock()
+	defer growths.mu.Unlock()
+	if g, ok := vecGrowths.Load(t); ok {
+		return g.(*Growth)
+	}
+	name := "elib.Vec[" + t.String() + "]"
+	g := newGrowth(name, (*T)(nil))
+	// Distinct types may print the same (e.g. types local to different functions):
+	// number duplicates so that all appear in growth report.
+	for i := 2; !g.registerLocked(); i++ {
+		g.Name = fmt.Sprintf("%s#%d", name, i)
+	}
+	vecGrowths.Store(t, g)
+	return g
+}
+
+func (p *Vec[T]) Resize(n uint) {
+	c := Index(cap(*p))
+	l := Index(len(*p)) + Index(n)
+	if l > c {
+		c = VecGrowthOf[T]().NextCap(Index(len(*p)), c, l)
+		q := make([]T, l, c)
+		copy(q, *p)
+		*p = q
+	}
+	*p = (*p)[:l]
+}
+
+func (p *Vec[T]) validate(newLen uint, zero *T) *T {
+	c := Index(cap(*p))
+	lʹ := Index(len(*p))
+	l := Index(newLen)
+	if l <= c {
+		// Need to reslice to larger length?
+		if l >= lʹ {
+			*p = (*p)[:l]
+		}
+		return &(*p)[l-1]
+	}
+	return p.validateSlowPath(zero, c, l, lʹ)
+}
+
+func (p *Vec[T]) validateSlowPath(zero *T, c, l, lʹ Index) *T {
+	if l > c {
+		cNext := VecGrowthOf[T]().NextCap(lʹ, c, l)
+		q := make([]T, cNext, cNext)
+		copy(q, *p)
+		if zero != nil {
+			for i := c; i < cNext; i++ {
+				q[i] = *zero
+			}
+		}
+		*p = q[:l]
+	}
+	if l > lʹ {
+		*p = (*p)[:l]
+	}
+	return &(*p)[l-1]
+}
+
+func (p *Vec[T]) Validate(i uint) *T {
+	return p.validate(i+1, nil)
+}
+
+func (p *Vec[T]) ValidateInit(i uint, zero T) *T {
+	return p.validate(i+1, &zero)
+}
+
+func (p *Vec[T]) ValidateLen(l uint) (v *T) {
+	if l > 0 {
+		v = p.validate(l, nil)
+	}
+	return
+}
+
+func (p *Vec[T]) ValidateLenInit(l uint, zero T) (v *T) {
+	if l > 0 {
+		v = p.validate(l, &zero)
+	}
+	return
+}
+
+func (p Vec[T]) Len() uint { return uint(len(p)) }
+
+// PoolOf is a pool of elements with same semantics as pool.tmpl generated pools.
+type PoolOf[T any] struct {
+	Pool
+	Data Vec[T]
+}
+
+func (p *PoolOf[T]) GetIndex() (i uint) {
+	l := uint(len(p.Data))
+	i = p.Pool.GetIndex(l)
+	if i >= l {
+		p.Validate(i)
+	}
+	return i
+}
+
+func (p *PoolOf[T]) PutIndex(i uint) (ok bool) {
+	return p.Pool.PutIndex(i)
+}
+
+func (p *PoolOf[T]) IsFree(i uint) (v bool) {
+	v = i >= uint(len(p.Data))
+	if !v {
+		v = p.Pool.IsFree(i)
+	}
+	return
+}
+
+func (p *PoolOf[T]) Resize(n uint)   { p.Data.Resize(n) }
+func (p *PoolOf[T]) Validate(i uint) { p.Data.Validate(i) }
+
+func (p *PoolOf[T]) Elts() uint {
+	return uint(len(p.Data)) - p.FreeLen()
+}
+
+func (p *PoolOf[T]) Len() uint {
+	return uint(len(p.Data))
+}
+
+func (p *PoolOf[T]) Foreach(f func(x T)) {
+	for i := range p.Data {
+		if !p.Pool.IsFree(uint(i)) {
+			f(p.Data[i])
+		}
+	}
+}
+
+func (p *PoolOf[T]) ForeachIndex(f func(i uint)) {
+	for i := range p.Data {
+		if !p.Pool.IsFree(uint(i)) {
+			f(uint(i))
+		}
+	}
+}
+
+// SetConcurrent puts pool in concurrent mode with fixed capacity of maxLen elements (see pool.tmpl).
+func (p *PoolOf[T]) SetConcurrent(batch, maxLen uint) {
+	p.Pool.SetConcurrent(batch, maxLen)
+	p.Validate(maxLen - 1)
+}
+
+// GetHandle allocates new element returning its index and handle.
+func (p *PoolOf[T]) GetHandle() (i uint, h PoolHandle) {
+	i = p.GetIndex()
+	h = p.Handle(i)
+	return
+}
+
+// ValidateHandle returns index for handle or error if handle is stale.
+func (p *PoolOf[T]) ValidateHandle(h PoolHandle) (i uint, err error) {
+	if i = h.Index(); i >= uint(len(p.Data)) {
+		err = ErrPoolStaleHandle
+		return
+	}
+	return p.Pool.ValidateHandle(h)
+}
+
+// PutHandle frees element for handle.  Stale handles are not freed.
+func (p *PoolOf[T]) PutHandle(h PoolHandle) (err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		p.PutIndex(i)
+	}
+	return
+}
+
+// GetByHandle returns pointer to element for handle or error if handle is stale.
+func (p *PoolOf[T]) GetByHandle(h PoolHandle) (x *T, err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		x = &p.Data[i]
+	}
+	return
+}
+
+// TypedHeap allocates variable sized slices of elements with same semantics as heap.tmpl generated heaps.
+type TypedHeap[T any] struct {
+	Heap
+	Data Vec[T]
+	ids  Vec[Index]
+}
+
+func (p *TypedHeap[T]) GetAligned(size, log2Alignment uint) (offset uint) {
+	id, offset := p.Heap.GetAligned(size, log2Alignment)
+	p.Validate(offset + size - 1)
+	for i := uint(0); i < size; i++ {
+		p.ids[offset+i] = id
+	}
+	return
+}
+
+func (p *TypedHeap[T]) Get(size uint) uint { return p.GetAligned(size, 0) }
+
+func (p *TypedHeap[T]) Put(offset uint) {
+	p.Heap.Put(p.Id(offset))
+}
+
+// Resize object at offset to given size moving data when object can not be resized in place.
+// Returns new offset of object.
+func (p *TypedHeap[T]) Resize(offset, size uint) (newOffset uint) {
+	id := p.Id(offset)
+	l := p.Len(id)
+	id, newOffset = p.Heap.Resize(id, size)
+	p.Validate(newOffset + size - 1)
+	if newOffset != offset {
+		if l > size {
+			l = size
+		}
+		copy(p.Data[newOffset:newOffset+l], p.Data[offset:offset+l])
+	}
+	for i := uint(0); i < size; i++ {
+		p.ids[newOffset+i] = id
+	}
+	return
+}
+
+func (p *TypedHeap[T]) Validate(i uint) {
+	p.Data.Validate(i)
+	p.ids.Validate(i)
+}
+
+func (p *TypedHeap[T]) Id(offset uint) Index {
+	return p.ids[offset]
+}
+
+func (p *TypedHeap[T]) Slice(offset uint) []T {
+	l := p.Len(p.Id(offset))
+	return p.Data[offset : offset+l]
+}
+
+// PriorityQueue is a min priority queue of values with keys ordered by less.
+// Push returns an index which identifies element for DecreaseKey and Del until element is removed.
+type PriorityQueue[K, V any] struct {
+	fib     FibHeap
+	entries PoolOf[priorityQueueEntry[K, V]]
+	less    func(a, b K) bool
+}
+
+type priorityQueueEntry[K, V any] struct {
+	key   K
+	value V
+}
+
+// priorityQueueOrder implements Ordered for FibHeap on keys of queue.
+type priorityQueueOrder[K, V any] struct{ q *PriorityQueue[K, V] }
+
+func (o priorityQueueOrder[K, V]) Compare(i, j int) int {
+	q := o.q
+	a, b := q.entries.Data[i].key, q.entries.Data[j].key
+	switch {
+	case q.less(a, b):
+		return -1
+	case q.less(b, a):
+		return 1
+	}
+	return 0
+}
+
+func (q *PriorityQueue[K, V]) order() Ordered { return priorityQueueOrder[K, V]{q: q} }
+
+// Init sets key ordering; must be called before queue is used.
+func (q *PriorityQueue[K, V]) Init(less func(a, b K) bool) { q.less = less }
+
+// Push adds value with given key returning its index.
+func (q *PriorityQueue[K, V]) Push(key K, value V) (i uint) {
+	i = q.entries.GetIndex()
+	q.entries.Data[i] = priorityQueueEntry[K, V]{key: key, value: value}
+	q.fib.Add(i)
+	return
+}
+
+// Min returns index of element with minimum key without removing it.
+func (q *PriorityQueue[K, V]) Min() (i uint, ok bool) { return q.fib.Min(q.order()) }
+
+// PopMin removes element with minimum key.
+func (q *PriorityQueue[K, V]) PopMin() (key K, value V, ok bool) {
+	var i uint
+	if i, ok = q.fib.PopMin(q.order()); ok {
+		e := &q.entries.Data[i]
+		key, value = e.key, e.value
+		q.free(i)
+	}
+	return
+}
+
+// DecreaseKey sets key of element with index i.  Larger keys are allowed but cost a delete and re-add.
+func (q *PriorityQueue[K, V]) DecreaseKey(i uint, key K) {
+	e := &q.entries.Data[i]
+	if q.less(e.key, key) {
+		e.key = key
+		q.fib.Update(i)
+		return
+	}
+	e.key = key
+	q.fib.DecreaseKey(i, q.order())
+}
+
+// Del removes element with index i.
+func (q *PriorityQueue[K, V]) Del(i uint) {
+	q.fib.Del(i)
+	q.free(i)
+}
+
+func (q *PriorityQueue[K, V]) free(i uint) {
+	q.entries.Data[i] = priorityQueueEntry[K, V]{}
+	q.entries.PutIndex(i)
+}
+
+func (q *PriorityQueue[K, V]) Key(i uint) K   { return q.entries.Data[i].key }
+func (q *PriorityQueue[K, V]) Value(i uint) V { return q.entries.Data[i].value }
+func (q *PriorityQueue[K, V]) Len() uint      { return q.fib.Len() }
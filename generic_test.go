@@ -0,0 +1,135 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build go1.18
+
+package elib
+
+import (
+	"testing"
+)
+
+func TestGenericVec(t *testing.T) {
+	var v Vec[int]
+	*v.ValidateInit(9, -1) = 9
+	if v.Len() != 10 || v[9] != 9 || v[0] != -1 {
+		t.Fatalf("validate init: %v", v)
+	}
+	if cap(v) > 10 && v[:cap(v)][10] != -1 {
+		t.Fatalf("capacity beyond length not initialized")
+	}
+	v.Resize(5)
+	if v.Len() != 15 {
+		t.Fatalf("resize: len %d", v.Len())
+	}
+	if p := v.ValidateLen(0); p != nil {
+		t.Fatalf("validate len 0")
+	}
+}
+
+func TestGenericPool(t *testing.T) {
+	var p PoolOf[string]
+	i := p.GetIndex()
+	p.Data[i] = "a"
+	j, h := p.GetHandle()
+	p.Data[j] = "b"
+	p.PutIndex(i)
+	n := 0
+	p.Foreach(func(x string) {
+		if x != "b" {
+			t.Fatalf("foreach: %q", x)
+		}
+		n++
+	})
+	if n != 1 || p.Elts() != 1 || !p.IsFree(i) || p.IsFree(j) {
+		t.Fatalf("elts %d", p.Elts())
+	}
+	if x, err := p.GetByHandle(h); err != nil || *x != "b" {
+		t.Fatalf("get by handle: %v", err)
+	}
+	p.PutHandle(h)
+	if _, err := p.GetByHandle(h); err != ErrPoolStaleHandle {
+		t.Fatalf("stale handle: %v", err)
+	}
+}
+
+func TestGenericHeap(t *testing.T) {
+	var h TypedHeap[byte]
+	o := h.Get(4)
+	copy(h.Slice(o), "abcd")
+	o1 := h.Get(4)
+	o = h.Resize(o, 8)
+	if s := string(h.Slice(o)[:4]); s != "abcd" || len(h.Slice(o)) != 8 {
+		t.Fatalf("resize: %q", s)
+	}
+	h.Put(o1)
+	h.Put(o)
+	if u := h.GetUsage(); u.Used != 0 {
+		t.Fatalf("used %d", u.Used)
+	}
+}
+
+func TestGenericPriorityQueue(t *testing.T) {
+	var q PriorityQueue[int, string]
+	q.Init(func(a, b int) bool { return a < b })
+	a := q.Push(30, "a")
+	q.Push(10, "b")
+	c := q.Push(20, "c")
+	d := q.Push(40, "d")
+	if k, v, _ := q.PopMin(); k != 10 || v != "b" {
+		t.Fatalf("pop min: %d %s", k, v)
+	}
+	q.DecreaseKey(d, 5)
+	q.DecreaseKey(c, 50)
+	q.Del(a)
+	if q.Len() != 2 {
+		t.Fatalf("len %d", q.Len())
+	}
+	var got []string
+	for q.Len() > 0 {
+		_, v, _ := q.PopMin()
+		got = append(got, v)
+	}
+	if len(got) != 2 || got[0] != "d" || got[1] != "c" {
+		t.Fatalf("order %v", got)
+	}
+	if _, _, ok := q.PopMin(); ok {
+		t.Fatalf("pop from empty queue")
+	}
+}
+
+func TestVecGrowthOf(t *testing.T) {
+	type a struct{ x, y uint32 }
+	type b struct{ x uint8 }
+	ga, gb := VecGrowthOf[a](), VecGrowthOf[b]()
+	if ga == gb || ga != VecGrowthOf[a]() {
+		t.Fatal("generic vectors must have one growth per element type")
+	}
+	if ga.EltBytes != 8 || gb.EltBytes != 1 {
+		t.Fatalf("element bytes %d %d", ga.EltBytes, gb.EltBytes)
+	}
+	ga.Policy.MinCap = 100
+	var va Vec[a]
+	var vb Vec[b]
+	va.Validate(0)
+	vb.Validate(0)
+	if cap(va) != 100 || cap(vb) == 100 {
+		t.Errorf("per type policy not applied: cap %d %d", cap(va), cap(vb))
+	}
+	if x, ok := GetGrowth(ga.Name); !ok || x != ga {
+		t.Errorf("growth %s not registered", ga.Name)
+	}
+
+	// Local type with same printed name as a gets its own numbered growth.
+	gc := func() *Growth {
+		type a struct{ x uint16 }
+		return VecGrowthOf[a]()
+	}()
+	if gc == ga || gc.Name != ga.Name+"#2" || gc.EltBytes != 2 {
+		t.Errorf("growth %s for duplicate type name", gc.Name)
+	}
+	if x, ok := GetGrowth(ga.Name); !ok || x != ga {
+		t.Errorf("growth %s replaced by duplicate", ga.Name)
+	}
+}
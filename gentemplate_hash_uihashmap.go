@@ -0,0 +1,144 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// autogenerated: do not edit!
+// generated from gentemplate [gentemplate -d Package=elib -id uiHashMap -d HashType=uiHashMap -d KeyType=uiKey -d ValueType=uiValue -tags debug hash.tmpl]
+
+//+build debug
+
+package elib
+
+import (
+	"bytes"
+	"encoding/gob"
+	"unsafe"
+)
+
+// Typed hash table mapping keys to values.
+// Keys are hashed and compared by value so key type must be comparable and
+// should not contain pointers (strings, slices, ...) or padding.
+type uiHashMap struct {
+	Hash
+	pairs []uiHashMapPair
+	// Old pairs while incremental resize is in progress.
+	oldPairs []uiHashMapPair
+}
+
+type uiHashMapPair struct {
+	Key   uiKey
+	Value uiValue
+}
+
+type uiHashMapKey uiKey
+
+func (k *uiHashMapKey) HashKey(s *HashState) {
+	s.HashPointer(unsafe.Pointer(k), unsafe.Sizeof(*k))
+}
+
+func (k *uiHashMapKey) HashKeyEqual(h Hasher, i uint) bool {
+	return uiKey(*k) == h.(*uiHashMap).pair(i).Key
+}
+
+// Indices beyond pairs refer to old pairs during incremental resize.
+func (h *uiHashMap) pair(i uint) *uiHashMapPair {
+	if l := uint(len(h.pairs)); i >= l {
+		return &h.oldPairs[i-l]
+	}
+	return &h.pairs[i]
+}
+
+func (h *uiHashMap) HashIndex(s *HashState, i uint) {
+	k := (*uiHashMapKey)(&h.pair(i).Key)
+	k.HashKey(s)
+}
+
+func (h *uiHashMap) HashResize(newCap uint, rs []HashResizeCopy) {
+	dst := make([]uiHashMapPair, newCap)
+	for i := range rs {
+		dst[rs[i].Dst] = *h.pair(rs[i].Src)
+	}
+	h.pairs = dst
+	h.oldPairs = nil
+}
+
+func (h *uiHashMap) HashResizeStart(newCap uint) {
+	h.oldPairs = h.pairs
+	h.pairs = make([]uiHashMapPair, newCap)
+}
+
+func (h *uiHashMap) HashResizeCopy(rs []HashResizeCopy) {
+	for i := range rs {
+		h.pairs[rs[i].Dst] = h.oldPairs[rs[i].Src]
+		h.oldPairs[rs[i].Src] = uiHashMapPair{}
+	}
+}
+
+func (h *uiHashMap) HashResizeDone() { h.oldPairs = nil }
+
+// Pairs are saved with encoding/gob for Hash MarshalBinary/UnmarshalBinary.
+func (h *uiHashMap) HashMarshalBinary(cap uint) ([]byte, error) {
+	var b bytes.Buffer
+	err := gob.NewEncoder(&b).Encode(h.pairs[:cap])
+	return b.Bytes(), err
+}
+
+func (h *uiHashMap) HashUnmarshalBinary(cap uint, data []byte) (err error) {
+	var pairs []uiHashMapPair
+	if err = gob.NewDecoder(bytes.NewReader(data)).Decode(&pairs); err != nil {
+		return
+	}
+	h.pairs = make([]uiHashMapPair, cap)
+	copy(h.pairs, pairs)
+	h.oldPairs = nil
+	return
+}
+
+func (h *uiHashMap) UnmarshalBinary(data []byte) error {
+	h.Hasher = h
+	return h.Hash.UnmarshalBinary(data)
+}
+
+func (h *uiHashMap) Init(cap uint) { h.Hash.Init(h, cap) }
+
+func (h *uiHashMap) Get(k uiKey) (v uiValue, ok bool) {
+	var i uint
+	if i, ok = h.Hash.Get((*uiHashMapKey)(&k)); ok {
+		v = h.pair(i).Value
+	}
+	return
+}
+
+// Set value for key.  Returns true if key already exists.
+func (h *uiHashMap) Set(k uiKey, v uiValue) (exists bool) {
+	if h.Hasher == nil {
+		h.Hasher = h
+	}
+	var i uint
+	i, exists = h.Hash.Set((*uiHashMapKey)(&k))
+	p := h.pair(i)
+	p.Key = k
+	p.Value = v
+	return
+}
+
+// Unset key returning previous value.
+func (h *uiHashMap) Unset(k uiKey) (v uiValue, ok bool) {
+	if h.Hasher == nil {
+		return
+	}
+	var i uint
+	if i, ok = h.Hash.Unset((*uiHashMapKey)(&k)); ok {
+		p := h.pair(i)
+		v = p.Value
+		*p = uiHashMapPair{}
+	}
+	return
+}
+
+func (h *uiHashMap) Foreach(f func(k uiKey, v uiValue)) {
+	h.ForeachIndex(func(i uint) {
+		p := h.pair(i)
+		f(p.Key, p.Value)
+	})
+}
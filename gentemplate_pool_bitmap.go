@@ -12,6 +12,9 @@ type BitmapPool struct {
 	bitmaps []BitmapVec
 }
 
+// Growth policy and statistics for BitmapPool.
+var BitmapPoolGrowth = NewGrowth("elib.BitmapPool", (*BitmapVec)(nil))
+
 func (p *BitmapPool) GetIndex() (i uint) {
 	l := uint(len(p.bitmaps))
 	i = p.Pool.GetIndex(l)
@@ -37,7 +40,7 @@ func (p *BitmapPool) Resize(n uint) {
 	c := Index(cap(p.bitmaps))
 	l := Index(len(p.bitmaps) + int(n))
 	if l > c {
-		c = NextResizeCap(l)
+		c = BitmapPoolGrowth.NextCap(Index(len(p.bitmaps)), c, l)
 		q := make([]BitmapVec, l, c)
 		copy(q, p.bitmaps)
 		p.bitmaps = q
@@ -49,7 +52,7 @@ func (p *BitmapPool) Validate(i uint) {
 	c := Index(cap(p.bitmaps))
 	l := Index(i) + 1
 	if l > c {
-		c = NextResizeCap(l)
+		c = BitmapPoolGrowth.NextCap(Index(len(p.bitmaps)), c, l)
 		q := make([]BitmapVec, l, c)
 		copy(q, p.bitmaps)
 		p.bitmaps = q
@@ -82,3 +85,45 @@ func (p *BitmapPool) ForeachIndex(f func(i uint)) {
 		}
 	}
 }
+
+// SetConcurrent puts pool in concurrent mode with fixed capacity of maxLen elements.
+// Data is allocated up front so that elements do not move.  Each thread allocates via its own cache
+// (see Pool.NewCache) and must only access elements it has allocated.
+func (p *BitmapPool) SetConcurrent(batch, maxLen uint) {
+	p.Pool.SetConcurrent(batch, maxLen)
+	p.Validate(maxLen - 1)
+}
+
+// GetHandle allocates new element returning its index and handle.
+func (p *BitmapPool) GetHandle() (i uint, h PoolHandle) {
+	i = p.GetIndex()
+	h = p.Handle(i)
+	return
+}
+
+// ValidateHandle returns index for handle or error if handle is stale.
+func (p *BitmapPool) ValidateHandle(h PoolHandle) (i uint, err error) {
+	if i = h.Index(); i >= uint(len(p.bitmaps)) {
+		err = ErrPoolStaleHandle
+		return
+	}
+	return p.Pool.ValidateHandle(h)
+}
+
+// PutHandle frees element for handle.  Stale handles are not freed.
+func (p *BitmapPool) PutHandle(h PoolHandle) (err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		p.PutIndex(i)
+	}
+	return
+}
+
+// GetByHandle returns pointer to element for handle or error if handle is stale.
+func (p *BitmapPool) GetByHandle(h PoolHandle) (x *BitmapVec, err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		x = &p.bitmaps[i]
+	}
+	return
+}
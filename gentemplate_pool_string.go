@@ -12,6 +12,9 @@ type StringPool struct {
 	Strings []string
 }
 
+// Growth policy and statistics for StringPool.
+var StringPoolGrowth = NewGrowth("elib.StringPool", (*string)(nil))
+
 func (p *StringPool) GetIndex() (i uint) {
 	l := uint(len(p.Strings))
 	i = p.Pool.GetIndex(l)
@@ -37,7 +40,7 @@ func (p *StringPool) Resize(n uint) {
 	c := Index(cap(p.Strings))
 	l := Index(len(p.Strings) + int(n))
 	if l > c {
-		c = NextResizeCap(l)
+		c = StringPoolGrowth.NextCap(Index(len(p.Strings)), c, l)
 		q := make([]string, l, c)
 		copy(q, p.Strings)
 		p.Strings = q
@@ -49,7 +52,7 @@ func (p *StringPool) Validate(i uint) {
 	c := Index(cap(p.Strings))
 	l := Index(i) + 1
 	if l > c {
-		c = NextResizeCap(l)
+		c = StringPoolGrowth.NextCap(Index(len(p.Strings)), c, l)
 		q := make([]string, l, c)
 		copy(q, p.Strings)
 		p.Strings = q
@@ -82,3 +85,45 @@ func (p *StringPool) ForeachIndex(f func(i uint)) {
 		}
 	}
 }
+
+// SetConcurrent puts pool in concurrent mode with fixed capacity of maxLen elements.
+// Data is allocated up front so that elements do not move.  Each thread allocates via its own cache
+// (see Pool.NewCache) and must only access elements it has allocated.
+func (p *StringPool) SetConcurrent(batch, maxLen uint) {
+	p.Pool.SetConcurrent(batch, maxLen)
+	p.Validate(maxLen - 1)
+}
+
+// GetHandle allocates new element returning its index and handle.
+func (p *StringPool) GetHandle() (i uint, h PoolHandle) {
+	i = p.GetIndex()
+	h = p.Handle(i)
+	return
+}
+
+// ValidateHandle returns index for handle or error if handle is stale.
+func (p *StringPool) ValidateHandle(h PoolHandle) (i uint, err error) {
+	if i = h.Index(); i >= uint(len(p.Strings)) {
+		err = ErrPoolStaleHandle
+		return
+	}
+	return p.Pool.ValidateHandle(h)
+}
+
+// PutHandle frees element for handle.  Stale handles are not freed.
+func (p *StringPool) PutHandle(h PoolHandle) (err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		p.PutIndex(i)
+	}
+	return
+}
+
+// GetByHandle returns pointer to element for handle or error if handle is stale.
+func (p *StringPool) GetByHandle(h PoolHandle) (x *string, err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		x = &p.Strings[i]
+	}
+	return
+}
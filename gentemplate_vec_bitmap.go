@@ -9,11 +9,14 @@ package elib
 
 type BitmapVec []Bitmap
 
+// Growth policy and statistics for BitmapVec.
+var BitmapVecGrowth = NewGrowth("elib.BitmapVec", (*Bitmap)(nil))
+
 func (p *BitmapVec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = BitmapVecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]Bitmap, l, c)
 		copy(q, *p)
 		*p = q
@@ -38,7 +41,7 @@ func (p *BitmapVec) validate(new_len uint, zero *Bitmap) *Bitmap {
 func (p *BitmapVec) validateSlowPath(zero *Bitmap,
 	c, l, lʹ Index) *Bitmap {
 	if l > c {
-		cNext := NextResizeCap(l)
+		cNext := BitmapVecGrowth.NextCap(lʹ, c, l)
 		q := make([]Bitmap, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
@@ -9,11 +9,14 @@ package elib
 
 type BitmapsVec [][]Bitmap
 
+// Growth policy and statistics for BitmapsVec.
+var BitmapsVecGrowth = NewGrowth("elib.BitmapsVec", (*[]Bitmap)(nil))
+
 func (p *BitmapsVec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = BitmapsVecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([][]Bitmap, l, c)
 		copy(q, *p)
 		*p = q
@@ -38,7 +41,7 @@ func (p *BitmapsVec) validate(new_len uint, zero *[]Bitmap) *[]Bitmap {
 func (p *BitmapsVec) validateSlowPath(zero *[]Bitmap,
 	c, l, lʹ Index) *[]Bitmap {
 	if l > c {
-		cNext := NextResizeCap(l)
+		cNext := BitmapsVecGrowth.NextCap(lʹ, c, l)
 		q := make([][]Bitmap, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
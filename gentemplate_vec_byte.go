@@ -9,11 +9,14 @@ package elib
 
 type ByteVec []byte
 
+// Growth policy and statistics for ByteVec.
+var ByteVecGrowth = NewGrowth("elib.ByteVec", (*byte)(nil))
+
 func (p *ByteVec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = ByteVecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]byte, l, c)
 		copy(q, *p)
 		*p = q
@@ -38,7 +41,7 @@ func (p *ByteVec) validate(new_len uint, zero *byte) *byte {
 func (p *ByteVec) validateSlowPath(zero *byte,
 	c, l, lʹ Index) *byte {
 	if l > c {
-		cNext := NextResizeCap(l)
+		cNext := ByteVecGrowth.NextCap(lʹ, c, l)
 		q := make([]byte, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
@@ -9,11 +9,14 @@ package elib
 
 type fibNodeVec []fibNode
 
+// Growth policy and statistics for fibNodeVec.
+var fibNodeVecGrowth = NewGrowth("elib.fibNodeVec", (*fibNode)(nil))
+
 func (p *fibNodeVec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = fibNodeVecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]fibNode, l, c)
 		copy(q, *p)
 		*p = q
@@ -38,7 +41,7 @@ func (p *fibNodeVec) validate(new_len uint, zero *fibNode) *fibNode {
 func (p *fibNodeVec) validateSlowPath(zero *fibNode,
 	c, l, lʹ Index) *fibNode {
 	if l > c {
-		cNext := NextResizeCap(l)
+		cNext := fibNodeVecGrowth.NextCap(lʹ, c, l)
 		q := make([]fibNode, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
@@ -9,11 +9,14 @@ package elib
 
 type freeEltVec []freeElt
 
+// Growth policy and statistics for freeEltVec.
+var freeEltVecGrowth = NewGrowth("elib.freeEltVec", (*freeElt)(nil))
+
 func (p *freeEltVec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = freeEltVecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]freeElt, l, c)
 		copy(q, *p)
 		*p = q
@@ -38,7 +41,7 @@ func (p *freeEltVec) validate(new_len uint, zero *freeElt) *freeElt {
 func (p *freeEltVec) validateSlowPath(zero *freeElt,
 	c, l, lʹ Index) *freeElt {
 	if l > c {
-		cNext := NextResizeCap(l)
+		cNext := freeEltVecGrowth.NextCap(lʹ, c, l)
 		q := make([]freeElt, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
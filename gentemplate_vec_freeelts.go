@@ -9,11 +9,14 @@ package elib
 
 type freeEltsVec []freeEltVec
 
+// Growth policy and statistics for freeEltsVec.
+var freeEltsVecGrowth = NewGrowth("elib.freeEltsVec", (*freeEltVec)(nil))
+
 func (p *freeEltsVec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = freeEltsVecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]freeEltVec, l, c)
 		copy(q, *p)
 		*p = q
@@ -38,7 +41,7 @@ func (p *freeEltsVec) validate(new_len uint, zero *freeEltVec) *freeEltVec {
 func (p *freeEltsVec) validateSlowPath(zero *freeEltVec,
 	c, l, lʹ Index) *freeEltVec {
 	if l > c {
-		cNext := NextResizeCap(l)
+		cNext := freeEltsVecGrowth.NextCap(lʹ, c, l)
 		q := make([]freeEltVec, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
@@ -9,11 +9,14 @@ package elib
 
 type Int16Vec []int16
 
+// Growth policy and statistics for Int16Vec.
+var Int16VecGrowth = NewGrowth("elib.Int16Vec", (*int16)(nil))
+
 func (p *Int16Vec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = Int16VecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]int16, l, c)
 		copy(q, *p)
 		*p = q
@@ -38,7 +41,7 @@ func (p *Int16Vec) validate(new_len uint, zero *int16) *int16 {
 func (p *Int16Vec) validateSlowPath(zero *int16,
 	c, l, lʹ Index) *int16 {
 	if l > c {
-		cNext := NextResizeCap(l)
+		cNext := Int16VecGrowth.NextCap(lʹ, c, l)
 		q := make([]int16, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
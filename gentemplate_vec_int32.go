@@ -9,11 +9,14 @@ package elib
 
 type Int32Vec []int32
 
+// Growth policy and statistics for Int32Vec.
+var Int32VecGrowth = NewGrowth("elib.Int32Vec", (*int32)(nil))
+
 func (p *Int32Vec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = Int32VecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]int32, l, c)
 		copy(q, *p)
 		*p = q
@@ -38,7 +41,7 @@ func (p *Int32Vec) validate(new_len uint, zero *int32) *int32 {
 func (p *Int32Vec) validateSlowPath(zero *int32,
 	c, l, lʹ Index) *int32 {
 	if l > c {
-		cNext := NextResizeCap(l)
+		cNext := Int32VecGrowth.NextCap(lʹ, c, l)
 		q := make([]int32, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
@@ -9,11 +9,14 @@ package elib
 
 type Int64Vec []int64
 
+// Growth policy and statistics for Int64Vec.
+var Int64VecGrowth = NewGrowth("elib.Int64Vec", (*int64)(nil))
+
 func (p *Int64Vec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = Int64VecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]int64, l, c)
 		copy(q, *p)
 		*p = q
@@ -38,7 +41,7 @@ func (p *Int64Vec) validate(new_len uint, zero *int64) *int64 {
 func (p *Int64Vec) validateSlowPath(zero *int64,
 	c, l, lʹ Index) *int64 {
 	if l > c {
-		cNext := NextResizeCap(l)
+		cNext := Int64VecGrowth.NextCap(lʹ, c, l)
 		q := make([]int64, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
@@ -9,11 +9,14 @@ package elib
 
 type Int8Vec []int8
 
+// Growth policy and statistics for Int8Vec.
+var Int8VecGrowth = NewGrowth("elib.Int8Vec", (*int8)(nil))
+
 func (p *Int8Vec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = Int8VecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]int8, l, c)
 		copy(q, *p)
 		*p = q
@@ -38,7 +41,7 @@ func (p *Int8Vec) validate(new_len uint, zero *int8) *int8 {
 func (p *Int8Vec) validateSlowPath(zero *int8,
 	c, l, lʹ Index) *int8 {
 	if l > c {
-		cNext := NextResizeCap(l)
+		cNext := Int8VecGrowth.NextCap(lʹ, c, l)
 		q := make([]int8, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
@@ -11,11 +11,14 @@ package elib
 
 type randHeapObjVec []randHeapObj
 
+// Growth policy and statistics for randHeapObjVec.
+var randHeapObjVecGrowth = NewGrowth("elib.randHeapObjVec", (*randHeapObj)(nil))
+
 func (p *randHeapObjVec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = randHeapObjVecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]randHeapObj, l, c)
 		copy(q, *p)
 		*p = q
@@ -23,18 +26,59 @@ func (p *randHeapObjVec) Resize(n uint) {
 	*p = (*p)[:l]
 }
 
-func (p *randHeapObjVec) Validate(i uint) {
+func (p *randHeapObjVec) validate(new_len uint, zero *randHeapObj) *randHeapObj {
 	c := Index(cap(*p))
-	l := Index(i) + 1
+	lʹ := Index(len(*p))
+	l := Index(new_len)
+	if l <= c {
+		// Need to reslice to larger length?
+		if l >= lʹ {
+			*p = (*p)[:l]
+		}
+		return &(*p)[l-1]
+	}
+	return p.validateSlowPath(zero, c, l, lʹ)
+}
+
+func (p *randHeapObjVec) validateSlowPath(zero *randHeapObj,
+	c, l, lʹ Index) *randHeapObj {
 	if l > c {
-		c = NextResizeCap(l)
-		q := make([]randHeapObj, l, c)
+		cNext := randHeapObjVecGrowth.NextCap(lʹ, c, l)
+		q := make([]randHeapObj, cNext, cNext)
 		copy(q, *p)
-		*p = q
+		if zero != nil {
+			for i := c; i < cNext; i++ {
+				q[i] = *zero
+			}
+		}
+		*p = q[:l]
 	}
-	if l > Index(len(*p)) {
+	if l > lʹ {
 		*p = (*p)[:l]
 	}
+	return &(*p)[l-1]
+}
+
+func (p *randHeapObjVec) Validate(i uint) *randHeapObj {
+	return p.validate(i+1, (*randHeapObj)(nil))
+}
+
+func (p *randHeapObjVec) ValidateInit(i uint, zero randHeapObj) *randHeapObj {
+	return p.validate(i+1, &zero)
+}
+
+func (p *randHeapObjVec) ValidateLen(l uint) (v *randHeapObj) {
+	if l > 0 {
+		v = p.validate(l, (*randHeapObj)(nil))
+	}
+	return
+}
+
+func (p *randHeapObjVec) ValidateLenInit(l uint, zero randHeapObj) (v *randHeapObj) {
+	if l > 0 {
+		v = p.validate(l, &zero)
+	}
+	return
 }
 
 func (p randHeapObjVec) Len() uint { return uint(len(p)) }
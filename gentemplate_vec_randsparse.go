@@ -11,11 +11,14 @@ package elib
 
 type randSparseVec []randSparse
 
+// Growth policy and statistics for randSparseVec.
+var randSparseVecGrowth = NewGrowth("elib.randSparseVec", (*randSparse)(nil))
+
 func (p *randSparseVec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = randSparseVecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]randSparse, l, c)
 		copy(q, *p)
 		*p = q
@@ -23,18 +26,59 @@ func (p *randSparseVec) Resize(n uint) {
 	*p = (*p)[:l]
 }
 
-func (p *randSparseVec) Validate(i uint) {
+func (p *randSparseVec) validate(new_len uint, zero *randSparse) *randSparse {
 	c := Index(cap(*p))
-	l := Index(i) + 1
+	lʹ := Index(len(*p))
+	l := Index(new_len)
+	if l <= c {
+		// Need to reslice to larger length?
+		if l >= lʹ {
+			*p = (*p)[:l]
+		}
+		return &(*p)[l-1]
+	}
+	return p.validateSlowPath(zero, c, l, lʹ)
+}
+
+func (p *randSparseVec) validateSlowPath(zero *randSparse,
+	c, l, lʹ Index) *randSparse {
 	if l > c {
-		c = NextResizeCap(l)
-		q := make([]randSparse, l, c)
+		cNext := randSparseVecGrowth.NextCap(lʹ, c, l)
+		q := make([]randSparse, cNext, cNext)
 		copy(q, *p)
-		*p = q
+		if zero != nil {
+			for i := c; i < cNext; i++ {
+				q[i] = *zero
+			}
+		}
+		*p = q[:l]
 	}
-	if l > Index(len(*p)) {
+	if l > lʹ {
 		*p = (*p)[:l]
 	}
+	return &(*p)[l-1]
+}
+
+func (p *randSparseVec) Validate(i uint) *randSparse {
+	return p.validate(i+1, (*randSparse)(nil))
+}
+
+func (p *randSparseVec) ValidateInit(i uint, zero randSparse) *randSparse {
+	return p.validate(i+1, &zero)
+}
+
+func (p *randSparseVec) ValidateLen(l uint) (v *randSparse) {
+	if l > 0 {
+		v = p.validate(l, (*randSparse)(nil))
+	}
+	return
+}
+
+func (p *randSparseVec) ValidateLenInit(l uint, zero randSparse) (v *randSparse) {
+	if l > 0 {
+		v = p.validate(l, &zero)
+	}
+	return
 }
 
 func (p randSparseVec) Len() uint { return uint(len(p)) }
@@ -9,11 +9,14 @@ package elib
 
 type StringVec []string
 
+// Growth policy and statistics for StringVec.
+var StringVecGrowth = NewGrowth("elib.StringVec", (*string)(nil))
+
 func (p *StringVec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = StringVecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]string, l, c)
 		copy(q, *p)
 		*p = q
@@ -38,7 +41,7 @@ func (p *StringVec) validate(new_len uint, zero *string) *string {
 func (p *StringVec) validateSlowPath(zero *string,
 	c, l, lʹ Index) *string {
 	if l > c {
-		cNext := NextResizeCap(l)
+		cNext := StringVecGrowth.NextCap(lʹ, c, l)
 		q := make([]string, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
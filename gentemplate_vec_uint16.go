@@ -9,11 +9,14 @@ package elib
 
 type Uint16Vec []uint16
 
+// Growth policy and statistics for Uint16Vec.
+var Uint16VecGrowth = NewGrowth("elib.Uint16Vec", (*uint16)(nil))
+
 func (p *Uint16Vec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = Uint16VecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]uint16, l, c)
 		copy(q, *p)
 		*p = q
@@ -38,7 +41,7 @@ func (p *Uint16Vec) validate(new_len uint, zero *uint16) *uint16 {
 func (p *Uint16Vec) validateSlowPath(zero *uint16,
 	c, l, lʹ Index) *uint16 {
 	if l > c {
-		cNext := NextResizeCap(l)
+		cNext := Uint16VecGrowth.NextCap(lʹ, c, l)
 		q := make([]uint16, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
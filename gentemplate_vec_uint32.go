@@ -9,11 +9,14 @@ package elib
 
 type Uint32Vec []uint32
 
+// Growth policy and statistics for Uint32Vec.
+var Uint32VecGrowth = NewGrowth("elib.Uint32Vec", (*uint32)(nil))
+
 func (p *Uint32Vec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = Uint32VecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]uint32, l, c)
 		copy(q, *p)
 		*p = q
@@ -38,7 +41,7 @@ func (p *Uint32Vec) validate(new_len uint, zero *uint32) *uint32 {
 func (p *Uint32Vec) validateSlowPath(zero *uint32,
 	c, l, lʹ Index) *uint32 {
 	if l > c {
-		cNext := NextResizeCap(l)
+		cNext := Uint32VecGrowth.NextCap(lʹ, c, l)
 		q := make([]uint32, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
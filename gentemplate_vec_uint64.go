@@ -9,11 +9,14 @@ package elib
 
 type Uint64Vec []uint64
 
+// Growth policy and statistics for Uint64Vec.
+var Uint64VecGrowth = NewGrowth("elib.Uint64Vec", (*uint64)(nil))
+
 func (p *Uint64Vec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = Uint64VecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]uint64, l, c)
 		copy(q, *p)
 		*p = q
@@ -38,7 +41,7 @@ func (p *Uint64Vec) validate(new_len uint, zero *uint64) *uint64 {
 func (p *Uint64Vec) validateSlowPath(zero *uint64,
 	c, l, lʹ Index) *uint64 {
 	if l > c {
-		cNext := NextResizeCap(l)
+		cNext := Uint64VecGrowth.NextCap(lʹ, c, l)
 		q := make([]uint64, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
@@ -9,11 +9,14 @@ package elib
 
 type Uint8Vec []uint8
 
+// Growth policy and statistics for Uint8Vec.
+var Uint8VecGrowth = NewGrowth("elib.Uint8Vec", (*uint8)(nil))
+
 func (p *Uint8Vec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = Uint8VecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]uint8, l, c)
 		copy(q, *p)
 		*p = q
@@ -38,7 +41,7 @@ func (p *Uint8Vec) validate(new_len uint, zero *uint8) *uint8 {
 func (p *Uint8Vec) validateSlowPath(zero *uint8,
 	c, l, lʹ Index) *uint8 {
 	if l > c {
-		cNext := NextResizeCap(l)
+		cNext := Uint8VecGrowth.NextCap(lʹ, c, l)
 		q := make([]uint8, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
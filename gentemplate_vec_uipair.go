@@ -11,11 +11,14 @@ package elib
 
 type uiPairVec []uiPair
 
+// Growth policy and statistics for uiPairVec.
+var uiPairVecGrowth = NewGrowth("elib.uiPairVec", (*uiPair)(nil))
+
 func (p *uiPairVec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = uiPairVecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]uiPair, l, c)
 		copy(q, *p)
 		*p = q
@@ -23,18 +26,59 @@ func (p *uiPairVec) Resize(n uint) {
 	*p = (*p)[:l]
 }
 
-func (p *uiPairVec) Validate(i uint) {
+func (p *uiPairVec) validate(new_len uint, zero *uiPair) *uiPair {
 	c := Index(cap(*p))
-	l := Index(i) + 1
+	lʹ := Index(len(*p))
+	l := Index(new_len)
+	if l <= c {
+		// Need to reslice to larger length?
+		if l >= lʹ {
+			*p = (*p)[:l]
+		}
+		return &(*p)[l-1]
+	}
+	return p.validateSlowPath(zero, c, l, lʹ)
+}
+
+func (p *uiPairVec) validateSlowPath(zero *uiPair,
+	c, l, lʹ Index) *uiPair {
 	if l > c {
-		c = NextResizeCap(l)
-		q := make([]uiPair, l, c)
+		cNext := uiPairVecGrowth.NextCap(lʹ, c, l)
+		q := make([]uiPair, cNext, cNext)
 		copy(q, *p)
-		*p = q
+		if zero != nil {
+			for i := c; i < cNext; i++ {
+				q[i] = *zero
+			}
+		}
+		*p = q[:l]
 	}
-	if l > Index(len(*p)) {
+	if l > lʹ {
 		*p = (*p)[:l]
 	}
+	return &(*p)[l-1]
+}
+
+func (p *uiPairVec) Validate(i uint) *uiPair {
+	return p.validate(i+1, (*uiPair)(nil))
+}
+
+func (p *uiPairVec) ValidateInit(i uint, zero uiPair) *uiPair {
+	return p.validate(i+1, &zero)
+}
+
+func (p *uiPairVec) ValidateLen(l uint) (v *uiPair) {
+	if l > 0 {
+		v = p.validate(l, (*uiPair)(nil))
+	}
+	return
+}
+
+func (p *uiPairVec) ValidateLenInit(l uint, zero uiPair) (v *uiPair) {
+	if l > 0 {
+		v = p.validate(l, &zero)
+	}
+	return
 }
 
 func (p uiPairVec) Len() uint { return uint(len(p)) }
@@ -9,11 +9,14 @@ package elib
 
 type WordVec []Word
 
+// Growth policy and statistics for WordVec.
+var WordVecGrowth = NewGrowth("elib.WordVec", (*Word)(nil))
+
 func (p *WordVec) Resize(n uint) {
 	c := Index(cap(*p))
 	l := Index(len(*p)) + Index(n)
 	if l > c {
-		c = NextResizeCap(l)
+		c = WordVecGrowth.NextCap(Index(len(*p)), c, l)
 		q := make([]Word, l, c)
 		copy(q, *p)
 		*p = q
@@ -38,7 +41,7 @@ func (p *WordVec) validate(new_len uint, zero *Word) *Word {
 func (p *WordVec) validateSlowPath(zero *Word,
 	c, l, lʹ Index) *Word {
 	if l > c {
-		cNext := NextResizeCap(l)
+		cNext := WordVecGrowth.NextCap(lʹ, c, l)
 		q := make([]Word, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
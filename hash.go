@@ -86,37 +86,38 @@ func (s *HashState) MixPointer(h0, h1, h2, h3 hash64, p unsafe.Pointer, size uin
 	n := int(size)
 	i := 0
 
-	n8 := n &^ 7
-
-	for i+4*8 <= n8 {
+	for i+4*8 <= n {
 		h0, h1, h2, h3 = s.MixUint64(h0, h1, h2, h3,
-			s.get64(p, i*0*8), s.get64(p, i*1*8),
-			s.get64(p, i*2*8), s.get64(p, i*3*8))
+			s.get64(p, i+0*8), s.get64(p, i+1*8),
+			s.get64(p, i+2*8), s.get64(p, i+3*8))
 		i += 4 * 8
 	}
 
-	if i+2*8 <= n8 {
-		h0, h1, h2, h3 = s.MixUint64(h0, h1, h2, h3, s.get64(p, i*0*8), s.get64(p, i*1*8), 0, 0)
+	if i+2*8 <= n {
+		h0, h1, h2, h3 = s.MixUint64(h0, h1, h2, h3, s.get64(p, i+0*8), s.get64(p, i+1*8), 0, 0)
 		i += 2 * 8
 	}
 
-	if i+1*8 <= n8 {
+	if i+1*8 <= n {
 		h2 += hash64(s.get64(p, i))
 		i += 1 * 8
 	}
 
-	n4 := (n - i) &^ 3
-	if i+1*4 <= n4 {
+	if i+1*4 <= n {
 		h3 += hash64(s.get32(p, i))
 		i += 1 * 4
 	}
 
-	n2 := (n - i) &^ 1
-	if i+1*2 <= n2 {
+	if i+1*2 <= n {
 		h3 += hash64(s.get16(p, i)) << 32
 		i += 1 * 2
 	}
 
+	if i < n {
+		h3 += hash64(*(*uint8)(PointerAdd(p, uintptr(i)))) << 48
+		i += 1
+	}
+
 	return h0, h1, h2, h3
 }
 
@@ -136,11 +137,18 @@ func (s *HashState) HashUint64(x0, x1, x2, x3 uint64) {
 	s.Finalize(h0, h1, h2, h3)
 }
 
+// Size of blocks mixed by MixUint64.
+const hashBlockBytes = 4 * 8
+
 func (s *HashState) HashPointer(p unsafe.Pointer, size uintptr) {
 	h0, h1, h2, h3 := s.Init()
-	// Mix in data length.
+	// Mix full blocks; then data length and remaining bytes.
+	nb := size &^ (hashBlockBytes - 1)
+	h0, h1, h2, h3 = s.MixPointer(h0, h1, h2, h3, p, nb)
 	h0 += hash64(size)
-	h0, h1, h2, h3 = s.MixPointer(h0, h1, h2, h3, p, size)
+	if nb < size {
+		h0, h1, h2, h3 = s.MixPointer(h0, h1, h2, h3, PointerAdd(p, nb), size-nb)
+	}
 	s.Finalize(h0, h1, h2, h3)
 }
 
@@ -153,36 +161,66 @@ type stats struct {
 func (s *stats) compare(x uint) { s.compares += uint64(x) }
 func (s *stats) search(x uint)  { s.searches += uint64(x); s.calls += 1 }
 
-type Hash struct {
-	Hasher            Hasher
+type hashTable struct {
 	seed              HashState
 	cap               Cap
 	log2Cap           [2]uint8
 	log2EltsPerBucket uint8
-	eltsPerBucket     uint32
 	limit0            uint32
 	bitDiffs          []bitDiff
 	maxBucketBitDiffs []bitDiff
-	nElts             uint
-	resizeCopies      []HashResizeCopy
-	stats             struct {
+}
+
+type Hash struct {
+	Hasher Hasher
+	hashTable
+	nElts        uint
+	resizeCopies []HashResizeCopy
+	resize       hashResize
+	// Called before Hasher's HashResize (used by ConcurrentHash).
+	beforeResize func()
+	stats        struct {
 		grows           uint64
 		copies          uint64
 		get, set, unset stats
 	}
 }
 
+// State for incremental resize.
+type hashResize struct {
+	// Number of old table buckets to migrate per Get/Set/Unset.
+	// Zero disables incremental resize.
+	bucketsPerOp uint
+
+	// Old table being migrated into new table or nil when no resize is in progress.
+	old *hashTable
+
+	// Next old table bucket to migrate.
+	bucket uint
+
+	// Capacity of new table as given to HashResizeStart.
+	// Indices >= newCap refer to old table elements offset by newCap.
+	newCap uint
+
+	// Number of elements remaining in old table.
+	nElts uint
+
+	stats struct {
+		starts, buckets, copies, fallbacks uint64
+	}
+}
+
 // Bit difference plus 1.
 type bitDiff uint8
 
 func (d bitDiff) isValid() bool        { return d != 0 }
 func (d *bitDiff) invalidate()         { *d = 0 }
 func (d bitDiff) match(diff uint) bool { return diff+1 == uint(d) }
-func (d *bitDiff) set(h *Hash, baseIndex, diff uint) {
+func (d *bitDiff) set(t *hashTable, baseIndex, diff uint) {
 	bd := bitDiff(1 + diff)
-	bi := baseIndex >> h.log2EltsPerBucket
-	if bd > h.maxBucketBitDiffs[bi] {
-		h.maxBucketBitDiffs[bi] = bd
+	bi := baseIndex >> t.log2EltsPerBucket
+	if bd > t.maxBucketBitDiffs[bi] {
+		t.maxBucketBitDiffs[bi] = bd
 	}
 	*d = bd
 }
@@ -198,6 +236,25 @@ type Hasher interface {
 	HashResize(newCap uint, copies []HashResizeCopy)
 }
 
+// HasherIncremental is implemented by Hashers which support incremental resize.
+// While a resize is in progress old and new tables coexist: indices i >= newCap as given to
+// HashResizeStart refer to element i - newCap of old table.  This holds for HashIndex and for copy
+// sources of HashResize until HashResize returns, even though Hash may have grown further by then.
+// HashResize may be called while a resize is in progress: copies then move elements from
+// both tables into a single new table and old table is no longer needed.
+type HasherIncremental interface {
+	Hasher
+
+	// New table of given capacity is allocated and old table is kept.
+	HashResizeStart(newCap uint)
+
+	// Copy elements from old table (Src) to new table (Dst).
+	HashResizeCopy(copies []HashResizeCopy)
+
+	// All elements have been copied; old table is no longer needed.
+	HashResizeDone()
+}
+
 type HasherKey interface {
 	// Compute hash for key.
 	HashKey(s *HashState)
@@ -206,22 +263,22 @@ type HasherKey interface {
 	HashKeyEqual(h Hasher, i uint) bool
 }
 
-func (h *Hash) capMask(i uint) uint { return uint(1)<<h.log2Cap[i] - 1 }
-func (h *HashState) limit() uint32  { return uint32(h[0] >> 32) }
-func (h *HashState) offset() hash64 { return h[1] }
+func (t *hashTable) capMask(i uint) uint { return uint(1)<<t.log2Cap[i] - 1 }
+func (h *HashState) limit() uint32       { return uint32(h[0] >> 32) }
+func (h *HashState) offset() hash64      { return h[1] }
 
-func (h *Hash) baseIndex(s *HashState) uint {
+func (t *hashTable) baseIndex(s *HashState) uint {
 	is_table_1 := uint(0)
-	if s.limit() > h.limit0 {
+	if s.limit() > t.limit0 {
 		is_table_1 = 1
 	}
-	return uint(s.offset())&h.capMask(is_table_1) + (is_table_1 << h.log2Cap[0])
+	return uint(s.offset())&t.capMask(is_table_1) + (is_table_1 << t.log2Cap[0])
 }
 
-func (h *Hash) baseIndexForKey(s *HashState, k HasherKey) uint {
-	*s = h.seed
+func (t *hashTable) baseIndexForKey(s *HashState, k HasherKey) uint {
+	*s = t.seed
 	k.HashKey(s)
-	return h.baseIndex(s)
+	return t.baseIndex(s)
 }
 
 func (h *Hash) baseIndexForIndex(s *HashState, i uint) uint {
@@ -232,17 +289,18 @@ func (h *Hash) baseIndexForIndex(s *HashState, i uint) uint {
 
 func (h *Hash) empty() bool { return len(h.bitDiffs) == 0 }
 
-func (h *Hash) searchBase(baseIndex uint, st *stats, k HasherKey) (matchDiff uint, ok bool) {
-	n := uint(1) << h.log2EltsPerBucket
+// Search table for key.  Offset is added to table indices passed to HashKeyEqual.
+func (t *hashTable) searchBase(r Hasher, offset, baseIndex uint, st *stats, k HasherKey) (matchDiff uint, ok bool) {
+	n := uint(1) << t.log2EltsPerBucket
 	matchDiff = n
-	bucketIndex := baseIndex >> h.log2EltsPerBucket
-	maxValidDiff := h.maxBucketBitDiffs[bucketIndex]
+	bucketIndex := baseIndex >> t.log2EltsPerBucket
+	maxValidDiff := t.maxBucketBitDiffs[bucketIndex]
 	diff := uint(0)
 	for ; diff < n; diff++ {
 		i := baseIndex ^ diff
-		if bd := h.bitDiffs[i]; bd.match(diff) {
+		if bd := t.bitDiffs[i]; bd.match(diff) {
 			st.compare(1)
-			if k.HashKeyEqual(h.Hasher, i) {
+			if k.HashKeyEqual(r, offset+i) {
 				matchDiff = diff
 				ok = true
 				break
@@ -255,19 +313,32 @@ func (h *Hash) searchBase(baseIndex uint, st *stats, k HasherKey) (matchDiff uin
 	return
 }
 
-func (h *Hash) searchKey(s *HashState, st *stats, k HasherKey) (baseIndex, matchDiff uint, ok bool) {
+// Search for key in table and, when incremental resize is in progress, old table.
+// Returns base index in table and index of matching key.
+func (h *Hash) searchKey(s *HashState, st *stats, k HasherKey) (baseIndex, i uint, ok bool) {
+	var mi uint
 	baseIndex = h.baseIndexForKey(s, k)
-	matchDiff, ok = h.searchBase(baseIndex, st, k)
+	if mi, ok = h.searchBase(h.Hasher, 0, baseIndex, st, k); ok {
+		i = baseIndex ^ mi
+		return
+	}
+	if o := h.resize.old; o != nil {
+		offset := h.Cap()
+		bi := o.baseIndexForKey(s, k)
+		if mi, ok = o.searchBase(h.Hasher, offset, bi, st, k); ok {
+			i = offset + (bi ^ mi)
+		}
+	}
 	return
 }
 
 // Search for an empty slot for key at index i.
-func (h *Hash) searchFreeIndex(baseIndex uint) (i, diff uint, ok bool) {
-	n := uint(1) << h.log2EltsPerBucket
+func (t *hashTable) searchFreeIndex(baseIndex uint) (i, diff uint, ok bool) {
+	n := uint(1) << t.log2EltsPerBucket
 	for ; diff < n; diff++ {
 		i = baseIndex ^ diff
-		if bd := h.bitDiffs[i]; !bd.isValid() {
-			h.bitDiffs[i].set(h, baseIndex, diff)
+		if bd := t.bitDiffs[i]; !bd.isValid() {
+			t.bitDiffs[i].set(t, baseIndex, diff)
 			ok = true
 			return
 		}
@@ -282,22 +353,53 @@ func (h *Hash) searchIndex(s *HashState, ki uint) (i uint, ok bool) {
 	return
 }
 
+// Table and index within table for given hash index.
+func (h *Hash) tableIndex(i uint) (t *hashTable, ti uint) {
+	t, ti = &h.hashTable, i
+	if c := h.Cap(); i >= c && h.resize.old != nil {
+		t, ti = h.resize.old, i-c
+	}
+	return
+}
+
+func (h *Hash) isValid(i uint) bool {
+	t, ti := h.tableIndex(i)
+	return t.bitDiffs[ti].isValid()
+}
+
+// Number of valid indices: includes old table when resize is in progress.
+func (h *Hash) nIndices() (n uint) {
+	n = uint(len(h.bitDiffs))
+	if o := h.resize.old; o != nil {
+		n += uint(len(o.bitDiffs))
+	}
+	return
+}
+
 func (h *Hash) ForeachIndex(f func(i uint)) {
 	for i := range h.bitDiffs {
 		if h.bitDiffs[i].isValid() {
 			f(uint(i))
 		}
 	}
+	if o := h.resize.old; o != nil {
+		offset := h.Cap()
+		for i := range o.bitDiffs {
+			if o.bitDiffs[i].isValid() {
+				f(offset + uint(i))
+			}
+		}
+	}
 }
 
 func (h *Hash) nextValidIndex(i, nth uint) uint {
 	if i == ^uint(0) {
 		i = 0
 	}
-	n := uint(len(h.bitDiffs))
+	n := h.nIndices()
 	count := uint(0)
 	for i < n {
-		if h.bitDiffs[i].isValid() {
+		if h.isValid(i) {
 			if count == nth {
 				return i
 			}
@@ -318,35 +420,40 @@ func (h *Hash) RandIndex() uint {
 
 func (h *Hash) Elts() uint         { return h.nElts }
 func (h *Hash) Cap() uint          { return uint(h.cap) }
-func (h *Hash) IsFree(i uint) bool { return !h.bitDiffs[i].isValid() }
+func (h *Hash) IsFree(i uint) bool { return !h.isValid(i) }
+
+// SetIncrementalResize enables incremental resize migrating given number of buckets per Get/Set/Unset.
+// Hasher must implement HasherIncremental.  Zero disables incremental resize.
+func (h *Hash) SetIncrementalResize(bucketsPerOp uint) { h.resize.bucketsPerOp = bucketsPerOp }
+
+// IsResizing returns true while incremental resize is in progress.
+func (h *Hash) IsResizing() bool { return h.resize.old != nil }
 
 func (h *Hash) Get(k HasherKey) (i uint, ok bool) {
 	if h.empty() {
 		return
 	}
-	var (
-		s      HashState
-		bi, mi uint
-	)
-	if bi, mi, ok = h.searchKey(&s, &h.stats.get, k); ok {
-		i = bi ^ mi
-	}
+	var s HashState
+	h.resizeStep(&s)
+	_, i, ok = h.searchKey(&s, &h.stats.get, k)
 	return i, ok
 }
 
 func (h *Hash) Set(k HasherKey) (i uint, exists bool) {
 	var (
-		bi, fi, mi uint
-		s          HashState
+		bi, fi uint
+		s      HashState
 	)
 	nonEmpty := !h.empty()
+	if nonEmpty {
+		h.resizeStep(&s)
+	}
 	for {
 		if nonEmpty {
-			bi, mi, exists = h.searchKey(&s, &h.stats.set, k)
+			bi, i, exists = h.searchKey(&s, &h.stats.set, k)
 		}
 		if exists {
 			// Key already exists.
-			i = bi ^ mi
 			return
 		}
 
@@ -357,17 +464,25 @@ func (h *Hash) Set(k HasherKey) (i uint, exists bool) {
 		if foundFree {
 			// Use up free slot in bucket.
 			i = bi ^ fi
-			h.bitDiffs[i].set(h, bi, fi)
+			h.bitDiffs[i].set(&h.hashTable, bi, fi)
 			h.nElts++
 			return
 		}
 
-		// Bucket full: grow hash and copy elements.
-		save := h.bitDiffs
-		for {
-			h.grow()
-			if h.copy(&s, save) {
-				break
+		switch {
+		case h.resize.old != nil:
+			// Bucket full while resize is in progress: give up and copy both tables.
+			h.resizeFallback(&s)
+		case nonEmpty && h.resizeStart():
+			// Bucket full: start incremental resize.
+		default:
+			// Bucket full: grow hash and copy elements.
+			save := h.bitDiffs
+			for {
+				h.grow()
+				if h.copy(&s, save, nil) {
+					break
+				}
 			}
 		}
 		nonEmpty = true
@@ -376,26 +491,38 @@ func (h *Hash) Set(k HasherKey) (i uint, exists bool) {
 }
 
 func (h *Hash) Unset(k HasherKey) (i uint, ok bool) {
-	var (
-		bi, mi uint
-		s      HashState
-	)
-	bi, mi, ok = h.searchKey(&s, &h.stats.unset, k)
+	if h.empty() {
+		return
+	}
+	var s HashState
+	h.resizeStep(&s)
+	_, i, ok = h.searchKey(&s, &h.stats.unset, k)
 	if ok {
-		i = bi ^ mi
-		h.bitDiffs[i].invalidate()
+		t, ti := h.tableIndex(i)
+		t.bitDiffs[ti].invalidate()
+		if t != &h.hashTable {
+			h.resize.nElts--
+		}
 		h.nElts--
 	}
 	return
 }
 
-func (h *Hash) Clear() {
+func (t *hashTable) clear() {
 	// Compiler will optimize into memclr.
-	for i := range h.bitDiffs {
-		h.bitDiffs[i] = 0
+	for i := range t.bitDiffs {
+		t.bitDiffs[i] = 0
 	}
-	for i := range h.maxBucketBitDiffs {
-		h.maxBucketBitDiffs[i] = 0
+	for i := range t.maxBucketBitDiffs {
+		t.maxBucketBitDiffs[i] = 0
+	}
+}
+
+func (h *Hash) Clear() {
+	h.hashTable.clear()
+	if o := h.resize.old; o != nil {
+		o.clear()
+		h.resize.nElts = 0
 	}
 	h.nElts = 0
 }
@@ -418,59 +545,74 @@ func (h *Hash) Init(r Hasher, cap uint) {
 	r.HashResize(uint(h.cap), h.resizeCopies)
 }
 
-func (h *Hash) alloc() {
-	log2c0, log2c1 := h.cap.Log2()
-	h.log2Cap[0] = uint8(log2c0)
-	h.log2Cap[1] = 0
+// Set table parameters derived from capacity.
+func (t *hashTable) setCap() {
+	log2c0, log2c1 := t.cap.Log2()
+	t.log2Cap[0] = uint8(log2c0)
+	t.log2Cap[1] = 0
 	if log2c1 != CapNil {
-		h.log2Cap[1] = uint8(log2c1)
+		t.log2Cap[1] = uint8(log2c1)
 	}
 
 	// For approx occupancy of .5 = 2^-1, probability of a full bucket of size M is 2^-(1 + M).
 	// So with N_BUCKETS = (2^l0 + 2^l1) / 2^M we have the probability that at least one bucket
 	// is full is (2^l0 + 2^l1) / 2^(2M + 1) ~ 1.  So, we set l0 = 2M + 1.
-	h.log2EltsPerBucket = uint8((log2c0 - 1) / 2)
+	t.log2EltsPerBucket = uint8((log2c0 - 1) / 2)
 
 	// Since bit diff is only 8 bits, cap bucket size.
-	if h.log2EltsPerBucket > 7 {
-		h.log2EltsPerBucket = 7
+	if t.log2EltsPerBucket > 7 {
+		t.log2EltsPerBucket = 7
 	}
 
 	if log2c1 == CapNil {
 		// No limit for table 0.
-		h.limit0 = ^uint32(0)
+		t.limit0 = ^uint32(0)
 	} else {
 		// Capacity must be even number of buckets.
-		if h.log2EltsPerBucket > h.log2Cap[1] {
-			log2c1 = Cap(h.log2EltsPerBucket)
-			h.cap = (1 << log2c0) | (1 << log2c1)
-			h.log2Cap[1] = uint8(log2c1)
+		if t.log2EltsPerBucket > t.log2Cap[1] {
+			log2c1 = Cap(t.log2EltsPerBucket)
+			t.cap = (1 << log2c0) | (1 << log2c1)
+			t.log2Cap[1] = uint8(log2c1)
 		}
 
 		// 2^32 2^i_0 / (2^i_0 + 2^i_1).
-		h.limit0 = uint32((uint64(1) << (32 + log2c0)) / uint64(h.cap))
+		t.limit0 = uint32((uint64(1) << (32 + log2c0)) / uint64(t.cap))
 	}
+}
+
+func (t *hashTable) nBuckets() uint { return uint(t.cap >> t.log2EltsPerBucket) }
+
+func (h *Hash) alloc() {
+	h.setCap()
 
 	for i := range h.seed {
 		h.seed[i] = hash64(rand.Int63())
 	}
 
 	h.bitDiffs = make([]bitDiff, h.cap)
-	nBuckets := h.cap >> h.log2EltsPerBucket
-	h.maxBucketBitDiffs = make([]bitDiff, nBuckets)
+	h.maxBucketBitDiffs = make([]bitDiff, h.nBuckets())
 	h.nElts = 0
 	return
 }
 
-func (h *Hash) copy(s *HashState, bds []bitDiff) (ok bool) {
+// Copy elements from old tables into newly allocated table.
+// Indices in second table are offset by length of first table.
+func (h *Hash) copy(s *HashState, bds0, bds1 []bitDiff) (ok bool) {
 	h.stats.copies++
-	if cap(h.resizeCopies) < len(bds) {
-		h.resizeCopies = make([]HashResizeCopy, len(bds))
+	if l := len(bds0) + len(bds1); cap(h.resizeCopies) < l {
+		h.resizeCopies = make([]HashResizeCopy, l)
 	}
-	var src, dst, n, l uint
-	l = uint(len(bds))
+	var src, dst, n, l, l0 uint
+	l0 = uint(len(bds0))
+	l = l0 + uint(len(bds1))
 	for src = 0; src < l; src++ {
-		if bds[src].isValid() {
+		var valid bool
+		if src < l0 {
+			valid = bds0[src].isValid()
+		} else {
+			valid = bds1[src-l0].isValid()
+		}
+		if valid {
 			if dst, ok = h.searchIndex(s, src); ok {
 				h.resizeCopies[n].Src = src
 				h.resizeCopies[n].Dst = dst
@@ -484,12 +626,99 @@ func (h *Hash) copy(s *HashState, bds []bitDiff) (ok bool) {
 		if h.resizeCopies != nil {
 			h.resizeCopies = h.resizeCopies[:n]
 		}
+		if h.beforeResize != nil {
+			h.beforeResize()
+		}
 		h.Hasher.HashResize(h.Cap(), h.resizeCopies)
 		h.nElts = n
 	}
 	return
 }
 
+// Start incremental resize if enabled and supported by Hasher.
+func (h *Hash) resizeStart() (ok bool) {
+	r := &h.resize
+	var hi HasherIncremental
+	if hi, ok = h.Hasher.(HasherIncremental); !ok || r.bucketsPerOp == 0 {
+		return false
+	}
+	r.stats.starts++
+	old := h.hashTable
+	r.old = &old
+	r.bucket = 0
+	r.nElts = h.nElts
+	h.grow()
+	h.nElts = r.nElts
+	r.newCap = h.Cap()
+	hi.HashResizeStart(r.newCap)
+	return
+}
+
+// Migrate a bounded number of old table buckets into new table.
+func (h *Hash) resizeStep(s *HashState) {
+	r := &h.resize
+	o := r.old
+	if o == nil {
+		return
+	}
+	hi := h.Hasher.(HasherIncremental)
+	offset := r.newCap
+	nBuckets := uint(len(o.maxBucketBitDiffs))
+	eltsPerBucket := uint(1) << o.log2EltsPerBucket
+	copies := h.resizeCopies[:0]
+	for b := uint(0); b < r.bucketsPerOp && r.bucket < nBuckets; b++ {
+		// Skip scan of buckets which have never been used.
+		if o.maxBucketBitDiffs[r.bucket].isValid() {
+			i0 := r.bucket << o.log2EltsPerBucket
+			for i := i0; i < i0+eltsPerBucket; i++ {
+				if !o.bitDiffs[i].isValid() {
+					continue
+				}
+				dst, ok := h.searchIndex(s, offset+i)
+				if !ok {
+					// New table bucket full: finish up by copying both tables.
+					if len(copies) > 0 {
+						hi.HashResizeCopy(copies)
+					}
+					h.resizeFallback(s)
+					return
+				}
+				copies = append(copies, HashResizeCopy{Src: i, Dst: dst})
+				o.bitDiffs[i].invalidate()
+				r.nElts--
+			}
+			o.maxBucketBitDiffs[r.bucket].invalidate()
+		}
+		r.bucket++
+		r.stats.buckets++
+	}
+	h.resizeCopies = copies
+	if len(copies) > 0 {
+		r.stats.copies += uint64(len(copies))
+		hi.HashResizeCopy(copies)
+	}
+	if r.bucket >= nBuckets {
+		r.old = nil
+		hi.HashResizeDone()
+	}
+}
+
+// Copy elements of both old and new tables into a single larger table.
+func (h *Hash) resizeFallback(s *HashState) {
+	r := &h.resize
+	r.stats.fallbacks++
+	// Old table indices stay offset by newCap (not by capacity after grow) until Hasher has been resized.
+	save0, save1 := h.bitDiffs[:r.newCap], r.old.bitDiffs
+	r.old = nil
+	r.nElts = 0
+	for {
+		h.grow()
+		if h.copy(s, save0, save1) {
+			break
+		}
+	}
+}
+
 func (s *stats) String() (v string) {
 	if s.calls != 0 {
 		v = fmt.Sprintf("search/call %.2f, cmp/call %.2f", float64(s.searches)/float64(s.calls), float64(s.compares)/float64(s.calls))
@@ -497,7 +726,19 @@ func (s *stats) String() (v string) {
 	return
 }
 
+func (r *hashResize) String() (v string) {
+	if r.stats.starts == 0 {
+		return
+	}
+	if o := r.old; o != nil {
+		v = fmt.Sprintf("in progress bucket %d/%d, old elts %d, ", r.bucket, len(o.maxBucketBitDiffs), r.nElts)
+	}
+	v += fmt.Sprintf("starts %d, buckets %d, copies %d, fallbacks %d",
+		r.stats.starts, r.stats.buckets, r.stats.copies, r.stats.fallbacks)
+	return
+}
+
 func (h *Hash) String() string {
-	return fmt.Sprintf("elts %d, cap %d, bucket: 2^%d, grows %d, copies %d\n    get: %s\n    set: %s\n  unset: %s",
-		h.Elts(), h.Cap(), h.log2EltsPerBucket, h.stats.grows, h.stats.copies, &h.stats.get, &h.stats.set, &h.stats.unset)
+	return fmt.Sprintf("elts %d, cap %d, bucket: 2^%d, grows %d, copies %d\n    get: %s\n    set: %s\n  unset: %s\n resize: %s",
+		h.Elts(), h.Cap(), h.log2EltsPerBucket, h.stats.grows, h.stats.copies, &h.stats.get, &h.stats.set, &h.stats.unset, &h.resize)
 }
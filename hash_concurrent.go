@@ -0,0 +1,237 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// ConcurrentHash allows any number of goroutines to Get without locks while writers
+// (serialized by a mutex) Set and Unset keys.
+//
+// Readers are protected by per bucket versions: writers make a bucket's version odd while
+// modifying it and readers retry lookups which overlap a write.  Resize allocates a new table
+// which is published atomically; readers of the old table retry.
+//
+// Readers never see writer's tables: each published table keeps its own copy of bucket state
+// which writers update with atomic stores.
+//
+// Since readers call Hasher's HashKeyEqual and Get's load concurrently with writers, caller's
+// storage must be read and written atomically (e.g. slots holding pointers to key/value pairs
+// accessed with sync/atomic).  HashKeyEqual may see keys being modified (the result is discarded)
+// and HashResize must publish new storage with a single atomic store.
+type ConcurrentHash struct {
+	// Serializes writers.
+	mu sync.Mutex
+
+	// Hash as seen by writers.
+	hash Hash
+
+	// Table published to readers (*concurrentHashTable).
+	table unsafe.Pointer
+
+	// Number of reader lookups retried due to concurrent writes.
+	retries uint64
+}
+
+type concurrentHashTable struct {
+	hashTable
+
+	hasher Hasher
+
+	// Per bucket version: odd while bucket is being written.
+	versions []uint32
+
+	// Reader copies of hashTable bitDiffs and maxBucketBitDiffs accessed atomically.
+	bitDiffs          []uint32
+	maxBucketBitDiffs []uint32
+
+	// Non-zero when table has been replaced by resize.
+	stale uint32
+}
+
+func (c *ConcurrentHash) load() *concurrentHashTable {
+	return (*concurrentHashTable)(atomic.LoadPointer(&c.table))
+}
+
+func (c *ConcurrentHash) publish() {
+	h := &c.hash
+	t := &concurrentHashTable{
+		hashTable:         h.hashTable,
+		hasher:            h.Hasher,
+		versions:          make([]uint32, len(h.maxBucketBitDiffs)),
+		bitDiffs:          make([]uint32, len(h.bitDiffs)),
+		maxBucketBitDiffs: make([]uint32, len(h.maxBucketBitDiffs)),
+	}
+	// Writer's slices are never read via published table.
+	t.hashTable.bitDiffs, t.hashTable.maxBucketBitDiffs = nil, nil
+	for i := range h.bitDiffs {
+		t.bitDiffs[i] = uint32(h.bitDiffs[i])
+	}
+	for i := range h.maxBucketBitDiffs {
+		t.maxBucketBitDiffs[i] = uint32(h.maxBucketBitDiffs[i])
+	}
+	atomic.StorePointer(&c.table, unsafe.Pointer(t))
+}
+
+// Copy writer's state for bucket containing index i to readers.
+func (t *concurrentHashTable) sync(h *Hash, i uint) {
+	b := i >> t.log2EltsPerBucket
+	i0 := b << t.log2EltsPerBucket
+	for j := i0; j < i0+1<<t.log2EltsPerBucket; j++ {
+		atomic.StoreUint32(&t.bitDiffs[j], uint32(h.bitDiffs[j]))
+	}
+	atomic.StoreUint32(&t.maxBucketBitDiffs[b], uint32(h.maxBucketBitDiffs[b]))
+}
+
+// Write done: publish new table if hash was resized otherwise update bucket containing index i.
+func (c *ConcurrentHash) written(t *concurrentHashTable, i uint) {
+	if atomic.LoadUint32(&t.stale) != 0 {
+		// Hash was resized: readers switch to new table.
+		c.publish()
+	} else {
+		t.sync(&c.hash, i)
+	}
+}
+
+func (t *concurrentHashTable) empty() bool { return len(t.bitDiffs) == 0 }
+
+// As hashTable.searchBase with atomic loads of bucket state.
+func (t *concurrentHashTable) searchBase(baseIndex uint, k HasherKey) (matchDiff uint, ok bool) {
+	n := uint(1) << t.log2EltsPerBucket
+	matchDiff = n
+	maxValidDiff := atomic.LoadUint32(&t.maxBucketBitDiffs[baseIndex>>t.log2EltsPerBucket])
+	for diff := uint(0); diff < n; diff++ {
+		i := baseIndex ^ diff
+		if bd := bitDiff(atomic.LoadUint32(&t.bitDiffs[i])); bd.match(diff) {
+			if k.HashKeyEqual(t.hasher, i) {
+				matchDiff = diff
+				ok = true
+				break
+			}
+		} else if diff+1 >= uint(maxValidDiff) {
+			break
+		}
+	}
+	return
+}
+
+// Version of bucket containing key or nil for empty table.
+func (t *concurrentHashTable) version(k HasherKey) *uint32 {
+	if t.empty() {
+		return nil
+	}
+	var s HashState
+	bi := t.baseIndexForKey(&s, k)
+	return &t.versions[bi>>t.log2EltsPerBucket]
+}
+
+// Make version odd before bucket is written and even afterwards.
+func writeVersion(v *uint32) {
+	if v != nil {
+		atomic.AddUint32(v, 1)
+	}
+}
+
+// Init must be called before hash is used.
+func (c *ConcurrentHash) Init(r Hasher, cap uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hash.Init(r, cap)
+	c.hash.beforeResize = func() {
+		atomic.StoreUint32(&c.load().stale, 1)
+	}
+	c.publish()
+}
+
+// Get looks up key without locking.  If found, load (when non-nil) is called with index of key
+// to read caller's data.  Load may be called more than once when lookup overlaps a write:
+// only the final call is valid.
+func (c *ConcurrentHash) Get(k HasherKey, load func(i uint)) (i uint, ok bool) {
+	var (
+		s  HashState
+		mi uint
+	)
+	for {
+		t := c.load()
+		if t == nil || t.empty() {
+			return
+		}
+		bi := t.baseIndexForKey(&s, k)
+		v := &t.versions[bi>>t.log2EltsPerBucket]
+		if v0 := atomic.LoadUint32(v); v0&1 == 0 {
+			if mi, ok = t.searchBase(bi, k); ok {
+				i = bi ^ mi
+				if load != nil {
+					load(i)
+				}
+			}
+			if atomic.LoadUint32(v) == v0 && atomic.LoadUint32(&t.stale) == 0 {
+				return
+			}
+		}
+		atomic.AddUint64(&c.retries, 1)
+	}
+}
+
+// Set key calling store (when non-nil) to write caller's data at index i while
+// readers are excluded from key's bucket.
+func (c *ConcurrentHash) Set(k HasherKey, store func(i uint, exists bool)) (i uint, exists bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := c.load()
+	v := t.version(k)
+	writeVersion(v)
+	i, exists = c.hash.Set(k)
+	if store != nil {
+		store(i, exists)
+	}
+	c.written(t, i)
+	writeVersion(v)
+	return
+}
+
+// Unset key calling clear (when non-nil) to remove caller's data at index i while
+// readers are excluded from key's bucket.
+func (c *ConcurrentHash) Unset(k HasherKey, clear func(i uint)) (i uint, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := c.load()
+	if t == nil {
+		return
+	}
+	v := t.version(k)
+	writeVersion(v)
+	if i, ok = c.hash.Unset(k); ok {
+		if clear != nil {
+			clear(i)
+		}
+		c.written(t, i)
+	}
+	writeVersion(v)
+	return
+}
+
+func (c *ConcurrentHash) Elts() uint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hash.Elts()
+}
+
+// ForeachIndex calls f for each valid index with writers excluded.
+func (c *ConcurrentHash) ForeachIndex(f func(i uint)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hash.ForeachIndex(f)
+}
+
+func (c *ConcurrentHash) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return fmt.Sprintf("%s\nretries: %d", &c.hash, atomic.LoadUint64(&c.retries))
+}
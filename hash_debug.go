@@ -14,7 +14,10 @@ import (
 	"math/rand"
 	"os"
 	"runtime/pprof"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unsafe"
 )
 
 type uiHash struct {
@@ -44,9 +47,14 @@ func (h *uiHash) HashResize(newCap uint, rs []HashResizeCopy) {
 	h.pairs = dst
 }
 
+//go:generate gentemplate -d Package=elib -id uiHashMap -d HashType=uiHashMap -d KeyType=uiKey -d ValueType=uiValue -tags debug hash.tmpl
+
 type testHash struct {
 	uiHash uiHash
 
+	// Same keys/values kept in typed hash map.
+	uiHashMap uiHashMap
+
 	pairs    uiPairVec
 	inserted Bitmap
 
@@ -62,6 +70,9 @@ type testHash struct {
 
 	nKeys Count
 
+	// Buckets to migrate per operation for incremental resize of typed hash map (zero means disabled).
+	incrementalResize uint
+
 	verbose  int
 	testTime bool
 
@@ -79,6 +90,7 @@ func HashTest() {
 	flag.Var(&t.printEvery, "print", "Number of iterations per print")
 	flag.Int64Var(&t.seed, "seed", 0, "Seed for random number generator")
 	flag.Var(&t.nKeys, "keys", "Number of random keys")
+	flag.UintVar(&t.incrementalResize, "incremental", 0, "Buckets to migrate per operation for incremental resize")
 	flag.IntVar(&t.verbose, "verbose", 0, "Be verbose")
 	flag.BoolVar(&t.testTime, "time", false, "Time hash functions")
 	flag.StringVar(&t.profile, "profile", "", "Write CPU profile to file")
@@ -103,6 +115,15 @@ func (t *testHash) doValidate() (err error) {
 			err = fmt.Errorf("get index got %d != want %d", i, pi)
 			return
 		}
+		v, ok := t.uiHashMap.Get(p.key)
+		if got, want := ok, t.inserted.Get(pi); got != want {
+			err = fmt.Errorf("map get ok %v != inserted %v", got, want)
+			return
+		}
+		if ok && v != p.value {
+			err = fmt.Errorf("map get value got %x != want %x", v, p.value)
+			return
+		}
 	}
 	return
 }
@@ -122,6 +143,19 @@ func (t *testHash) validate(h *Hash, iter int) (err error) {
 	return
 }
 
+// Save typed hash map and restore it into an empty map.
+func (t *testHash) validateMarshal() (err error) {
+	var b []byte
+	if b, err = t.uiHashMap.MarshalBinary(); err != nil {
+		return
+	}
+	t.uiHashMap = uiHashMap{}
+	if err = t.uiHashMap.UnmarshalBinary(b); err != nil {
+		return
+	}
+	return t.doValidate()
+}
+
 func runHashTest(t *testHash) (err error) {
 	if t.seed == 0 {
 		t.seed = int64(time.Now().Nanosecond())
@@ -156,6 +190,7 @@ func runHashTest(t *testHash) (err error) {
 	}
 
 	h.Hasher = h
+	t.uiHashMap.SetIncrementalResize(t.incrementalResize)
 	zero := uiPair{}
 	start := time.Now()
 	var iter int
@@ -169,12 +204,18 @@ func runHashTest(t *testHash) (err error) {
 				panic("exists")
 			}
 			h.pairs[i] = *p
+			if t.uiHashMap.Set(p.key, p.value) {
+				panic("map exists")
+			}
 		} else {
 			i, ok := h.Unset(&p.key)
 			if !ok {
 				panic("unset")
 			}
 			h.pairs[i] = zero
+			if v, ok := t.uiHashMap.Unset(p.key); !ok || v != p.value {
+				panic("map unset")
+			}
 		}
 
 		err = t.validate(&h.Hash, iter)
@@ -192,8 +233,14 @@ func runHashTest(t *testHash) (err error) {
 		}
 		iter++
 	}
+	if err = t.validateMarshal(); err != nil {
+		return
+	}
 	dt := time.Since(start)
 	fmt.Printf("%d iterations: %e iter/sec %s\n", iter, float64(iter)/dt.Seconds(), h)
+	if t.verbose != 0 {
+		fmt.Printf("map: %s\n", &t.uiHashMap)
+	}
 	return
 }
 
@@ -246,3 +293,120 @@ func (t *testHash) timeHash() {
 		fmt.Printf("unset: %.2f clocks/operation, %e per sec\n", tm.unset.ClocksPer(niter), tm.unset.PerSecond(niter))
 	}
 }
+
+// Hasher for concurrent hash test: storage is published atomically on resize and
+// each slot points to an immutable pair.
+type uiConcurrentHash struct {
+	ConcurrentHash
+	pairs unsafe.Pointer // *[]unsafe.Pointer of *uiPair
+}
+
+var uiConcurrentZeroPair uiPair
+
+func (h *uiConcurrentHash) getPairs() []unsafe.Pointer {
+	return *(*[]unsafe.Pointer)(atomic.LoadPointer(&h.pairs))
+}
+func (h *uiConcurrentHash) pair(i uint) *uiPair {
+	if p := (*uiPair)(atomic.LoadPointer(&h.getPairs()[i])); p != nil {
+		return p
+	}
+	return &uiConcurrentZeroPair
+}
+func (h *uiConcurrentHash) setPair(i uint, p *uiPair) {
+	atomic.StorePointer(&h.getPairs()[i], unsafe.Pointer(p))
+}
+
+type uiConcurrentKey uiKey
+
+func (k *uiConcurrentKey) HashKey(s *HashState) { (*uiKey)(k).HashKey(s) }
+func (k *uiConcurrentKey) HashKeyEqual(h Hasher, i uint) bool {
+	return uiKey(*k) == h.(*uiConcurrentHash).pair(i).key
+}
+func (h *uiConcurrentHash) HashIndex(s *HashState, i uint) { h.pair(i).key.HashKey(s) }
+func (h *uiConcurrentHash) HashResize(newCap uint, rs []HashResizeCopy) {
+	var src []unsafe.Pointer
+	if h.pairs != nil {
+		src = h.getPairs()
+	}
+	dst := make([]unsafe.Pointer, newCap)
+	for i := range rs {
+		dst[rs[i].Dst] = atomic.LoadPointer(&src[rs[i].Src])
+	}
+	atomic.StorePointer(&h.pairs, unsafe.Pointer(&dst))
+}
+
+// Value stored for each key so that readers can verify consistency.
+func (k uiKey) concurrentValue() uiValue { return uiValue(^k) }
+
+func runConcurrentHashTest(t *testHash, nReaders int) (err error) {
+	if t.seed == 0 {
+		t.seed = int64(time.Now().Nanosecond())
+	}
+	rand.Seed(t.seed)
+
+	keys := make([]uiKey, t.nKeys)
+	log2n := Word(t.nKeys).MaxLog2()
+	for i := range keys {
+		keys[i] = uiKey((uint64(rand.Int63()) << log2n) + uint64(i))
+	}
+
+	h := &uiConcurrentHash{}
+	h.Init(h, 0)
+
+	var (
+		wg     sync.WaitGroup
+		done   uint32
+		errors = make(chan error, nReaders)
+	)
+	for r := 0; r < nReaders; r++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			for atomic.LoadUint32(&done) == 0 {
+				k := uiConcurrentKey(keys[rng.Intn(len(keys))])
+				var p uiPair
+				if _, ok := h.Get(&k, func(i uint) { p = *h.pair(i) }); ok {
+					if uiKey(k) != p.key || p.value != p.key.concurrentValue() {
+						errors <- fmt.Errorf("concurrent get key %x: got %+v", k, p)
+						return
+					}
+				}
+			}
+		}(rand.Int63())
+	}
+
+	inserted := make([]bool, len(keys))
+	for iter := 0; iter < int(t.iterations); iter++ {
+		ki := rand.Intn(len(keys))
+		k := uiConcurrentKey(keys[ki])
+		if !inserted[ki] {
+			h.Set(&k, func(i uint, exists bool) {
+				h.setPair(i, &uiPair{key: uiKey(k), value: uiKey(k).concurrentValue()})
+			})
+		} else {
+			h.Unset(&k, func(i uint) { h.setPair(i, nil) })
+		}
+		inserted[ki] = !inserted[ki]
+	}
+	atomic.StoreUint32(&done, 1)
+	wg.Wait()
+
+	select {
+	case err = <-errors:
+		return
+	default:
+	}
+
+	for ki := range keys {
+		k := uiConcurrentKey(keys[ki])
+		if _, ok := h.Get(&k, nil); ok != inserted[ki] {
+			err = fmt.Errorf("concurrent get key %x: ok %v != inserted %v", k, ok, inserted[ki])
+			return
+		}
+	}
+	if t.verbose != 0 {
+		fmt.Printf("%s\n", h)
+	}
+	return
+}
@@ -0,0 +1,157 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// HasherBinaryMarshaler may be implemented by Hashers to save and restore key/value data
+// along with Hash binary snapshots.
+type HasherBinaryMarshaler interface {
+	// Marshal data for table with given capacity.
+	HashMarshalBinary(cap uint) ([]byte, error)
+
+	// Restore data for table with given capacity.
+	// Called after hash table has been restored.
+	HashUnmarshalBinary(cap uint, data []byte) error
+}
+
+// Version of binary snapshot format.
+// Version 2: HashPointer mixes all bytes of keys.
+const hashBinaryVersion = 2
+
+// Header: version, seed, capacity, number of elements.
+const hashBinaryHeaderBytes = 1 + 2*8 + 4 + 8
+
+var ErrHashBinaryCorrupt = errors.New("hash: corrupt binary snapshot")
+
+// MarshalBinary saves hash table and Hasher's data (if Hasher implements HasherBinaryMarshaler).
+// Incremental resize in progress is completed before saving.
+func (h *Hash) MarshalBinary() (b []byte, err error) {
+	var s HashState
+	for h.resize.old != nil {
+		h.resizeStep(&s)
+	}
+
+	var payload []byte
+	if m, ok := h.Hasher.(HasherBinaryMarshaler); ok {
+		if payload, err = m.HashMarshalBinary(h.Cap()); err != nil {
+			return
+		}
+	}
+
+	l := hashBinaryHeaderBytes + len(h.bitDiffs) + len(h.maxBucketBitDiffs) + 8 + len(payload)
+	b = make([]byte, l)
+	b[0] = hashBinaryVersion
+	i := 1
+	for j := range h.seed {
+		binary.LittleEndian.PutUint64(b[i:], uint64(h.seed[j]))
+		i += 8
+	}
+	binary.LittleEndian.PutUint32(b[i:], uint32(h.cap))
+	i += 4
+	binary.LittleEndian.PutUint64(b[i:], uint64(h.nElts))
+	i += 8
+	for j := range h.bitDiffs {
+		b[i+j] = byte(h.bitDiffs[j])
+	}
+	i += len(h.bitDiffs)
+	for j := range h.maxBucketBitDiffs {
+		b[i+j] = byte(h.maxBucketBitDiffs[j])
+	}
+	i += len(h.maxBucketBitDiffs)
+	binary.LittleEndian.PutUint64(b[i:], uint64(len(payload)))
+	i += 8
+	copy(b[i:], payload)
+	return
+}
+
+// UnmarshalBinary restores hash table saved by MarshalBinary without rehashing keys.
+// Hasher must be set by caller before restore.  If Hasher does not implement HasherBinaryMarshaler
+// HashResize is called with no copies to allocate storage; caller then restores data at saved indices.
+func (h *Hash) UnmarshalBinary(b []byte) (err error) {
+	if len(b) < hashBinaryHeaderBytes {
+		return ErrHashBinaryCorrupt
+	}
+	if v := b[0]; v != hashBinaryVersion {
+		return fmt.Errorf("hash: unknown binary snapshot version %d", v)
+	}
+	var t hashTable
+	i := 1
+	for j := range t.seed {
+		t.seed[j] = hash64(binary.LittleEndian.Uint64(b[i:]))
+		i += 8
+	}
+	t.cap = Cap(binary.LittleEndian.Uint32(b[i:]))
+	i += 4
+	nElts := uint(binary.LittleEndian.Uint64(b[i:]))
+	i += 8
+
+	// Capacity must be one Init or grow could have produced.
+	if t.cap != t.cap.Round(hashLog2CapMinUnit) {
+		return ErrHashBinaryCorrupt
+	}
+	t.setCap()
+	if uint32(t.cap) != binary.LittleEndian.Uint32(b[1+2*8:]) {
+		return ErrHashBinaryCorrupt
+	}
+	nb := t.nBuckets()
+	if t.cap != 0 && nb == 0 {
+		return ErrHashBinaryCorrupt
+	}
+	if uint(len(b)-i) < uint(t.cap)+nb+8 {
+		return ErrHashBinaryCorrupt
+	}
+	t.bitDiffs = make([]bitDiff, t.cap)
+	t.maxBucketBitDiffs = make([]bitDiff, nb)
+	n := uint(0)
+	for j := range t.bitDiffs {
+		d := bitDiff(b[i+j])
+		if d.isValid() {
+			n++
+		}
+		t.bitDiffs[j] = d
+	}
+	i += len(t.bitDiffs)
+	for j := range t.maxBucketBitDiffs {
+		t.maxBucketBitDiffs[j] = bitDiff(b[i+j])
+	}
+	i += len(t.maxBucketBitDiffs)
+	if n != nElts {
+		return ErrHashBinaryCorrupt
+	}
+	// Bit differences must lie within bucket and not exceed bucket maximum.
+	eltsPerBucket := bitDiff(1) << t.log2EltsPerBucket
+	for j, d := range t.bitDiffs {
+		if d > eltsPerBucket || d > t.maxBucketBitDiffs[j>>t.log2EltsPerBucket] {
+			return ErrHashBinaryCorrupt
+		}
+	}
+	for _, d := range t.maxBucketBitDiffs {
+		if d > eltsPerBucket {
+			return ErrHashBinaryCorrupt
+		}
+	}
+	l := binary.LittleEndian.Uint64(b[i:])
+	i += 8
+	if uint64(len(b)-i) != l {
+		return ErrHashBinaryCorrupt
+	}
+
+	h.hashTable = t
+	h.nElts = nElts
+	h.resize.old = nil
+	h.resize.nElts = 0
+
+	if m, ok := h.Hasher.(HasherBinaryMarshaler); ok {
+		err = m.HashUnmarshalBinary(h.Cap(), b[i:])
+	} else if h.Hasher != nil {
+		h.Hasher.HashResize(h.Cap(), nil)
+	}
+	return
+}
@@ -0,0 +1,107 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+import (
+	"unsafe"
+)
+
+// HashStream hashes data written in any number of pieces and implements hash.Hash64.
+// Hash of all data written is the same as HashState.HashPointer for same seed and data.
+type HashStream struct {
+	seed HashState
+
+	// Mixed hash state for full blocks written so far.
+	h [4]hash64
+
+	// Bytes written which do not yet make a full block.
+	buf  [hashBlockBytes]byte
+	nBuf int
+
+	// Total bytes written.
+	len uint64
+
+	// Set by Reset: zero value hashes with zero seed.
+	valid bool
+}
+
+// Seed sets seed and resets hash.
+func (w *HashStream) Seed(s HashState) {
+	w.seed = s
+	w.Reset()
+}
+
+func (w *HashStream) SeedUint64(s0, s1 uint64) { w.Seed(HashState{hash64(s0), hash64(s1)}) }
+
+func (w *HashStream) Reset() {
+	w.h[0], w.h[1], w.h[2], w.h[3] = w.seed.Init()
+	w.nBuf = 0
+	w.len = 0
+	w.valid = true
+}
+
+func (w *HashStream) mix(p unsafe.Pointer, n uintptr) {
+	w.h[0], w.h[1], w.h[2], w.h[3] = w.seed.MixPointer(w.h[0], w.h[1], w.h[2], w.h[3], p, n)
+}
+
+func (w *HashStream) Write(p []byte) (n int, err error) {
+	if !w.valid {
+		w.Reset()
+	}
+	n = len(p)
+	w.len += uint64(n)
+
+	// Fill partial block.
+	if w.nBuf > 0 {
+		c := copy(w.buf[w.nBuf:], p)
+		w.nBuf += c
+		p = p[c:]
+		if w.nBuf < hashBlockBytes {
+			return
+		}
+		w.mix(unsafe.Pointer(&w.buf[0]), hashBlockBytes)
+		w.nBuf = 0
+	}
+
+	// Mix full blocks directly from data.
+	if nb := len(p) &^ (hashBlockBytes - 1); nb > 0 {
+		w.mix(unsafe.Pointer(&p[0]), uintptr(nb))
+		p = p[nb:]
+	}
+
+	w.nBuf = copy(w.buf[:], p)
+	return
+}
+
+func (w *HashStream) WriteString(s string) (n int, err error) { return w.Write([]byte(s)) }
+
+// Finalize gives hash state for data written so far; stream is not modified.
+func (w *HashStream) Finalize(s *HashState) {
+	if !w.valid {
+		w.Reset()
+	}
+	*s = w.seed
+	h0, h1, h2, h3 := w.h[0], w.h[1], w.h[2], w.h[3]
+	h0 += hash64(w.len)
+	if w.nBuf > 0 {
+		h0, h1, h2, h3 = s.MixPointer(h0, h1, h2, h3, unsafe.Pointer(&w.buf[0]), uintptr(w.nBuf))
+	}
+	s.Finalize(h0, h1, h2, h3)
+}
+
+func (w *HashStream) Sum64() uint64 {
+	var s HashState
+	w.Finalize(&s)
+	return uint64(s[0])
+}
+
+// Sum appends big endian hash to b.
+func (w *HashStream) Sum(b []byte) []byte {
+	x := w.Sum64()
+	return append(b, byte(x>>56), byte(x>>48), byte(x>>40), byte(x>>32), byte(x>>24), byte(x>>16), byte(x>>8), byte(x))
+}
+
+func (w *HashStream) Size() int      { return 8 }
+func (w *HashStream) BlockSize() int { return hashBlockBytes }
@@ -0,0 +1,123 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/rand"
+	"testing"
+	"unsafe"
+)
+
+func TestHash(t *testing.T) {
+	c := testHash{
+		iterations:    10000,
+		nKeys:         1000,
+		validateEvery: 100,
+	}
+	err := runHashTest(&c)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHashIncrementalResize(t *testing.T) {
+	c := testHash{
+		iterations:        10000,
+		nKeys:             1000,
+		validateEvery:     100,
+		incrementalResize: 1,
+	}
+	err := runHashTest(&c)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConcurrentHash(t *testing.T) {
+	c := testHash{
+		iterations: 100000,
+		nKeys:      1000,
+	}
+	err := runConcurrentHashTest(&c, 4)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHashStream(t *testing.T) {
+	var w hash.Hash64 = &HashStream{}
+	s := w.(*HashStream)
+	b := make([]byte, 200)
+	for iter := 0; iter < 1000; iter++ {
+		n := rand.Intn(len(b))
+		rand.Read(b[:n])
+		seed := HashState{hash64(rand.Int63()), hash64(rand.Int63())}
+
+		// Write data in random sized pieces.
+		s.Seed(seed)
+		for i := 0; i < n; {
+			l := 1 + rand.Intn(n-i)
+			w.Write(b[i : i+l])
+			i += l
+		}
+		var got HashState
+		s.Finalize(&got)
+
+		want := seed
+		if n > 0 {
+			want.HashPointer(unsafe.Pointer(&b[0]), uintptr(n))
+		} else {
+			want.HashPointer(nil, 0)
+		}
+		if got != want {
+			t.Fatalf("size %d: stream %x != pointer %x", n, got, want)
+		}
+	}
+
+	// Odd trailing bytes must change hash.
+	s.Reset()
+	w.Write([]byte("abc"))
+	x := w.Sum64()
+	s.Reset()
+	w.Write([]byte("abd"))
+	if x == w.Sum64() {
+		t.Error("trailing byte ignored")
+	}
+}
+
+func TestHashBinaryCorrupt(t *testing.T) {
+	// Snapshot with given capacity, bit diffs and bucket maxima.
+	snapshot := func(cap uint32, nElts uint64, bitDiffs, maxBitDiffs []byte) []byte {
+		b := make([]byte, hashBinaryHeaderBytes)
+		b[0] = hashBinaryVersion
+		binary.LittleEndian.PutUint32(b[1+2*8:], cap)
+		binary.LittleEndian.PutUint64(b[1+2*8+4:], nElts)
+		b = append(b, bitDiffs...)
+		b = append(b, maxBitDiffs...)
+		return append(b, make([]byte, 8)...)
+	}
+	var h Hash
+	if err := h.UnmarshalBinary(snapshot(8, 1, []byte{2, 0, 0, 0, 0, 0, 0, 0}, []byte{2, 0, 0, 0})); err != nil {
+		t.Fatalf("valid snapshot: %v", err)
+	}
+	for _, c := range []struct {
+		name string
+		data []byte
+	}{
+		{"cap 1", snapshot(1, 0, []byte{0}, nil)},
+		{"cap 9", snapshot(9, 0, make([]byte, 9), make([]byte, 4))},
+		{"diff beyond bucket", snapshot(8, 1, []byte{3, 0, 0, 0, 0, 0, 0, 0}, []byte{3, 0, 0, 0})},
+		{"diff beyond bucket max", snapshot(8, 1, []byte{2, 0, 0, 0, 0, 0, 0, 0}, []byte{1, 0, 0, 0})},
+		{"element count", snapshot(8, 2, []byte{1, 0, 0, 0, 0, 0, 0, 0}, []byte{1, 0, 0, 0})},
+		{"truncated", snapshot(8, 0, make([]byte, 8), nil)},
+	} {
+		var h Hash
+		if err := h.UnmarshalBinary(c.data); err != ErrHashBinaryCorrupt {
+			t.Errorf("%s: expected corrupt error got %v", c.name, err)
+		}
+	}
+}
@@ -22,6 +22,9 @@ type Heap struct {
 	// "Size" 0 is for large sized chunks.
 	free freeEltsVec
 
+	// Bitmap of sizes with non-empty free lists so best fit finds smallest larger free list quickly.
+	freeNonEmpty BitmapVec
+
 	removed []Index
 
 	head, tail Index
@@ -34,6 +37,42 @@ type Heap struct {
 
 	// Max limit on heap size in elements.
 	maxLen Index
+
+	policy HeapPolicy
+}
+
+// HeapPolicy selects how free elements are chosen for allocation.
+type HeapPolicy uint8
+
+const (
+	// First free element large enough is used.
+	HeapFirstFit HeapPolicy = iota
+	// Smallest free element large enough is used.
+	HeapBestFit
+	// Sizes are rounded up to size classes (4 per power of 2) so freed elements are
+	// more likely to be reused exactly; otherwise first fit.
+	HeapSizeClass
+)
+
+var heapPolicyNames = [...]string{
+	HeapFirstFit:  "first-fit",
+	HeapBestFit:   "best-fit",
+	HeapSizeClass: "size-class",
+}
+
+func (p HeapPolicy) String() string { return heapPolicyNames[p] }
+
+// SetPolicy selects allocation policy.  Must be called before heap is used.
+func (heap *Heap) SetPolicy(p HeapPolicy) { heap.policy = p }
+func (heap *Heap) GetPolicy() HeapPolicy  { return heap.policy }
+
+// Round size up to size class for HeapSizeClass policy.
+func (heap *Heap) roundSize(size Index) Index {
+	if heap.policy != HeapSizeClass || size <= 8 {
+		return size
+	}
+	m := Index(1)<<(MinLog2(Word(size))-2) - 1
+	return (size + m) &^ m
 }
 
 func (heap *Heap) SetMaxLen(l uint) {
@@ -47,8 +86,9 @@ type HeapUsage struct {
 }
 
 func (heap *Heap) GetUsage() (u HeapUsage) {
-	for i := range heap.elts {
-		e := &heap.elts[i]
+	// Walk element list since removed elts are not part of heap.
+	for ei := heap.head; heap.len > 0 && ei != MaxIndex; ei = heap.elts[ei].next {
+		e := &heap.elts[ei]
 		size := uint64(heap.eltSize(e))
 		if e.isFree() {
 			u.Free += size
@@ -59,6 +99,70 @@ func (heap *Heap) GetUsage() (u HeapUsage) {
 	return
 }
 
+// HeapFragmentation gives detailed statistics of heap free space.
+type HeapFragmentation struct {
+	HeapUsage
+
+	// Number of used and free elements.
+	NUsed, NFree uint
+
+	// Size of largest free element.
+	LargestFree uint64
+
+	// Number of free elements with size in [2^i, 2^(i+1)).
+	FreeLog2Sizes []uint
+
+	// Number of elements on free list for each size class.
+	// Class 0 is for elements larger than largest size ever allocated.
+	FreeSizeClasses []uint
+}
+
+func (heap *Heap) GetFragmentation() (f HeapFragmentation) {
+	for ei := heap.head; heap.len > 0 && ei != MaxIndex; ei = heap.elts[ei].next {
+		e := &heap.elts[ei]
+		size := heap.eltSize(e)
+		if !e.isFree() {
+			f.Used += uint64(size)
+			f.NUsed++
+			continue
+		}
+		f.Free += uint64(size)
+		f.NFree++
+		if uint64(size) > f.LargestFree {
+			f.LargestFree = uint64(size)
+		}
+		l := MinLog2(Word(size))
+		for uint(len(f.FreeLog2Sizes)) <= l {
+			f.FreeLog2Sizes = append(f.FreeLog2Sizes, 0)
+		}
+		f.FreeLog2Sizes[l]++
+	}
+	f.FreeSizeClasses = make([]uint, len(heap.free))
+	for i := range heap.free {
+		f.FreeSizeClasses[i] = uint(len(heap.free[i]))
+	}
+	return
+}
+
+// Fraction of free space not in largest free element: 0 means no fragmentation.
+func (f *HeapFragmentation) Fragmentation() float64 {
+	if f.Free == 0 {
+		return 0
+	}
+	return 1 - float64(f.LargestFree)/float64(f.Free)
+}
+
+func (f *HeapFragmentation) String() (s string) {
+	s = fmt.Sprintf("used %d in %d elts, free %d in %d elts, largest free %d, fragmentation %.2f%%",
+		f.Used, f.NUsed, f.Free, f.NFree, f.LargestFree, 100*f.Fragmentation())
+	for i, n := range f.FreeLog2Sizes {
+		if n != 0 {
+			s += fmt.Sprintf("\n  free size %d-%d: %d", uint64(1)<<uint(i), uint64(1)<<uint(i+1)-1, n)
+		}
+	}
+	return
+}
+
 type freeElt Index
 
 //go:generate gentemplate -d Package=elib -id freeElt -d VecType=freeEltVec -d Type=freeElt vec.tmpl
@@ -73,6 +177,9 @@ type heapElt struct {
 
 	// Index of next and previous elements
 	next, prev Index
+
+	// Log2 alignment of offset requested by GetAligned; kept by Compact and Resize.
+	log2Align uint8
 }
 
 func (e *heapElt) isFree() bool {
@@ -124,6 +231,42 @@ func (heap *Heap) freeElt(ei, size Index) {
 	heap.free.Validate(uint(size))
 	heap.elts[ei].free = Index(len(heap.free[size]))
 	heap.free[size] = append(heap.free[size], freeElt(ei))
+	heap.freeNonEmpty.Alloc(uint(size) + 1)
+	heap.freeNonEmpty.Set(uint(size), true)
+}
+
+// Free list of given size has become empty.
+func (heap *Heap) freeListEmpty(size Index) {
+	if len(heap.free[size]) == 0 {
+		heap.freeNonEmpty.Unset(uint(size))
+	}
+}
+
+// Free elts larger than max size live on free list 0.  When max size grows, elts which
+// are no longer larger are moved to the free list for their size.
+func (heap *Heap) setMaxSize(size Index) {
+	if size <= heap.maxSize {
+		return
+	}
+	heap.maxSize = size
+	if len(heap.free) == 0 {
+		return
+	}
+	for i := 0; i < len(heap.free[0]); {
+		l := heap.free[0]
+		ei := Index(l[i])
+		s := heap.size(ei)
+		if s > size {
+			i++
+			continue
+		}
+		n := len(l) - 1
+		l[i] = l[n]
+		heap.elts[l[i]].free = Index(i)
+		heap.free[0] = l[:n]
+		heap.freeListEmpty(0)
+		heap.freeElt(ei, s)
+	}
 }
 
 var poison heapElt = heapElt{
@@ -136,7 +279,7 @@ var poison heapElt = heapElt{
 func (heap *Heap) removeFreeElt(ei, size Index) {
 	e := &heap.elts[ei]
 	fi := e.free
-	if size >= Index(len(heap.free)) {
+	if size > heap.maxSize {
 		size = 0
 	}
 	if l := Index(len(heap.free[size])); fi < l && heap.free[size][fi] == freeElt(ei) {
@@ -146,6 +289,7 @@ func (heap *Heap) removeFreeElt(ei, size Index) {
 			heap.elts[gi].free = fi
 		}
 		heap.free[size] = heap.free[size][:l-1]
+		heap.freeListEmpty(size)
 		*e = poison
 		heap.removed = append(heap.removed, ei)
 		return
@@ -185,13 +329,37 @@ func (heap *Heap) newElt() (ei Index) {
 	return
 }
 
-func (heap *Heap) Get(size uint) (id Index, offset uint) { return heap.get(size, Index(size)) }
+func (heap *Heap) Get(size uint) (id Index, offset uint) {
+	s := heap.roundSize(Index(size))
+	return heap.get(uint(s), s)
+}
+
+// Allocate element fi on free list l splitting off any space beyond size as new free element.
+func (heap *Heap) getFree(l, fi, size Index) (id Index, offset uint) {
+	ei := heap.free[l][fi]
+	e := &heap.elts[ei]
+	es := heap.eltSize(e)
+	if n := Index(len(heap.free[l])); fi < n-1 {
+		gi := heap.free[l][n-1]
+		heap.free[l][fi] = gi
+		heap.elts[gi].free = fi
+	}
+	heap.free[l] = heap.free[l][:len(heap.free[l])-1]
+	heap.freeListEmpty(l)
+
+	offset = uint(e.offset)
+	e.free = MaxIndex
+	id = Index(ei)
+
+	if es > size {
+		heap.freeAfter(id, es, es-size)
+	}
+	return
+}
 
 func (heap *Heap) get(sizeArg uint, size Index) (id Index, offset uint) {
 	// Keep track of largest size caller asks for.
-	if Index(sizeArg) > heap.maxSize {
-		heap.maxSize = Index(sizeArg)
-	}
+	heap.setMaxSize(Index(sizeArg))
 
 	if size <= 0 {
 		panic("size")
@@ -200,42 +368,32 @@ func (heap *Heap) get(sizeArg uint, size Index) (id Index, offset uint) {
 	// Quickly allocate from free list of given size.
 	if int(size) < len(heap.free) {
 		if l := len(heap.free[size]); l > 0 {
-			ei := heap.free[size][l-1]
-			e := &heap.elts[ei]
-			heap.free[size] = heap.free[size][:l-1]
-			e.free = MaxIndex
-			offset = uint(e.offset)
-			id = Index(ei)
-			return
+			return heap.getFree(size, Index(l-1), size)
+		}
+	}
+
+	// Best fit: smallest larger sized free list.
+	if heap.policy == HeapBestFit {
+		if l, ok := heap.freeNonEmpty.NextSet(uint(size) + 1); ok {
+			return heap.getFree(Index(l), Index(len(heap.free[l])-1), size)
 		}
 	}
 
 	// Search free list 0: where free objects > max requested size are kept.
 	if len(heap.free) > 0 {
-		l := Index(len(heap.free[0]))
-		for fi := Index(0); fi < l; fi++ {
-			ei := heap.free[0][fi]
-			e := &heap.elts[ei]
-			es := heap.eltSize(e)
-			fs := int(es) - int(size)
-			if fs < 0 {
+		best, bestSize := MaxIndex, MaxIndex
+		for fi := range heap.free[0] {
+			es := heap.eltSize(&heap.elts[heap.free[0][fi]])
+			if es < size || es >= bestSize {
 				continue
 			}
-			if fi < l-1 {
-				gi := heap.free[0][l-1]
-				heap.free[0][fi] = gi
-				heap.elts[gi].free = fi
-			}
-			heap.free[0] = heap.free[0][:l-1]
-
-			offset = uint(e.offset)
-			e.free = MaxIndex
-			id = Index(ei)
-
-			if fs > 0 {
-				heap.freeAfter(Index(ei), es, Index(fs))
+			best, bestSize = Index(fi), es
+			if heap.policy != HeapBestFit || es == size {
+				break
 			}
-			return
+		}
+		if best != MaxIndex {
+			return heap.getFree(0, best, size)
 		}
 	}
 
@@ -244,12 +402,14 @@ func (heap *Heap) get(sizeArg uint, size Index) (id Index, offset uint) {
 	}
 
 	if heap.len == 0 {
-		heap.head = 0
 		heap.tail = MaxIndex
 	}
 
 	ei := heap.newElt()
 	e := &heap.elts[ei]
+	if heap.len == 0 {
+		heap.head = ei
+	}
 
 	offset = uint(heap.len)
 	heap.len += size
@@ -301,11 +461,10 @@ func (heap *Heap) GetAligned(sizeArg, log2Alignment uint) (id Index, offset uint
 	// Adjust size for alignment so we guarantee a large enough block.
 	a := Index(1) << log2Alignment
 
-	size := sizeArg + uint(a) - 1
-	sa := Index(sizeArg)
-	s := Index(size)
+	sa := heap.roundSize(Index(sizeArg))
+	s := sa + a - 1
 
-	ei, offset := heap.get(sizeArg, s)
+	ei, offset := heap.get(uint(sa), s)
 	o := Index(offset)
 
 	// Aligned offset.
@@ -328,57 +487,197 @@ func (heap *Heap) GetAligned(sizeArg, log2Alignment uint) (id Index, offset uint
 		}
 	}
 
+	heap.elts[ei].log2Align = uint8(log2Alignment)
 	id = ei
 	offset = uint(ao)
 	return
 }
 
+// Merge free element before ei into ei.
+func (heap *Heap) mergePrev(ei Index) {
+	e := &heap.elts[ei]
+	pi := e.prev
+	prev := &heap.elts[pi]
+	ps := e.offset - prev.offset
+	e.offset = prev.offset
+	e.prev = prev.prev
+	if e.prev != MaxIndex {
+		heap.elts[e.prev].next = ei
+	}
+	heap.removeFreeElt(pi, ps)
+	if pi == heap.head {
+		heap.head = ei
+	}
+}
+
+// Merge free element after ei into ei.
+func (heap *Heap) mergeNext(ei Index) {
+	e := &heap.elts[ei]
+	ni := e.next
+	ns := heap.size(ni)
+	next := &heap.elts[ni]
+	e.next = next.next
+	if e.next != MaxIndex {
+		heap.elts[e.next].prev = ei
+	}
+	heap.removeFreeElt(ni, ns)
+	if ni == heap.tail {
+		heap.tail = ei
+	}
+}
+
+func (heap *Heap) isFreeElt(ei Index) bool { return ei != MaxIndex && heap.elts[ei].isFree() }
+
 func (heap *Heap) Put(ei Index) {
 	e := &heap.elts[ei]
 
 	if e.isFree() {
 		panic(fmt.Errorf("duplicate free %d", ei))
 	}
+	e.log2Align = 0
 
 	// If previous element is free combine free elements.
-	if e.prev != MaxIndex {
-		prev := &heap.elts[e.prev]
-		if prev.isFree() {
-			ps := e.offset - prev.offset
-			e.offset = prev.offset
-			pi := e.prev
-			e.prev = prev.prev
-			if e.prev != MaxIndex {
-				heap.elts[e.prev].next = ei
-			}
-			heap.removeFreeElt(pi, ps)
-			if pi == heap.head {
-				heap.head = ei
-			}
-		}
+	if heap.isFreeElt(e.prev) {
+		heap.mergePrev(ei)
 	}
 
 	// If next element is free also combine.
-	if e.next != MaxIndex {
-		next := &heap.elts[e.next]
-		if next.isFree() {
-			ni := e.next
-			ns := heap.size(ni)
-			e.next = next.next
-			if e.next != MaxIndex {
-				heap.elts[e.next].prev = ei
-			}
-			heap.removeFreeElt(ni, ns)
-			if ni == heap.tail {
-				heap.tail = ei
-			}
-		}
+	if heap.isFreeElt(e.next) {
+		heap.mergeNext(ei)
 	}
 
 	es := heap.size(ei)
 	heap.freeElt(ei, es)
 }
 
+// Free end of element beyond given size.
+func (heap *Heap) freeTail(ei, size Index) {
+	fi := heap.newEltAfter(ei)
+	e, f := &heap.elts[ei], &heap.elts[fi]
+	f.offset = e.offset + size
+	heap.Put(fi)
+}
+
+// Resize grows or shrinks element to given size.  Element is resized in place when possible
+// by absorbing following free element (or growing heap when element is last).  Otherwise
+// preceding free element is also absorbed and offset moves down.  As a last resort a new
+// element is allocated and the old one freed.  Returns (possibly new) id and offset;
+// when offset changes caller must move min(old, new size) elements of data (regions may overlap).
+// Alignment from GetAligned is not preserved when offset changes.
+func (heap *Heap) Resize(ei Index, sizeArg uint) (id Index, offset uint) {
+	e := &heap.elts[ei]
+	if e.isFree() {
+		panic(fmt.Errorf("resize of free elt %d", ei))
+	}
+	size := heap.roundSize(Index(sizeArg))
+	if size <= 0 {
+		panic("size")
+	}
+	heap.setMaxSize(size)
+
+	id = ei
+	es := heap.eltSize(e)
+	if size <= es {
+		if size < es {
+			heap.freeTail(ei, size)
+		}
+		offset = uint(heap.elts[ei].offset)
+		return
+	}
+
+	avail := es
+	nextFree := heap.isFreeElt(e.next)
+	if nextFree {
+		avail += heap.size(e.next)
+	}
+	atEnd := ei == heap.tail || nextFree && e.next == heap.tail
+	canGrow := atEnd && (heap.maxLen == 0 || heap.len+size-avail <= heap.maxLen)
+
+	prevSize := Index(0)
+	// Aligned elements can not slide down into previous free element.
+	if heap.isFreeElt(e.prev) && e.log2Align == 0 {
+		prevSize = e.offset - heap.elts[e.prev].offset
+	}
+
+	switch {
+	case avail >= size || canGrow:
+		if nextFree {
+			heap.mergeNext(ei)
+		}
+		if size > avail {
+			heap.len += size - avail
+		}
+	case avail+prevSize >= size:
+		if nextFree {
+			heap.mergeNext(ei)
+		}
+		heap.mergePrev(ei)
+		avail += prevSize
+	default:
+		id, offset = heap.GetAligned(sizeArg, uint(e.log2Align))
+		heap.Put(ei)
+		return
+	}
+	if avail > size {
+		heap.freeTail(ei, size)
+	}
+	offset = uint(heap.elts[ei].offset)
+	return
+}
+
+// Compact moves used elements towards start of heap so that all free space is at end of heap
+// and then releases free space at end.  Move is called for each element moved; caller must move
+// size elements of data from old to new offset (regions may overlap).  Element ids do not change.
+// Elements allocated by GetAligned keep their alignment: free space needed to align them is left
+// before them.  Returns number of elements released from end of heap.
+func (heap *Heap) Compact(move func(id Index, oldOffset, newOffset, size uint)) (released uint) {
+	if heap.len == 0 {
+		return
+	}
+	for ei := heap.head; ei != MaxIndex; ei = heap.elts[ei].next {
+		e := &heap.elts[ei]
+		if e.isFree() || !heap.isFreeElt(e.prev) {
+			continue
+		}
+		es, o := heap.eltSize(e), e.offset
+		a := Index(1) << e.log2Align
+		p := heap.elts[e.prev].offset
+		ao := (p + a - 1) &^ (a - 1)
+		if ao >= o {
+			continue
+		}
+		// Slide element down over previous free element leaving free space after element.
+		heap.mergePrev(ei)
+		if ao > p {
+			// Leave free space before element to keep alignment.
+			pi := heap.newEltBefore(ei)
+			heap.elts[pi].offset = p
+			heap.elts[ei].offset = ao
+			heap.Put(pi)
+		}
+		heap.freeTail(ei, es)
+		if move != nil {
+			move(ei, uint(o), uint(heap.elts[ei].offset), uint(es))
+		}
+	}
+
+	// Release free element at end of heap.
+	if ti := heap.tail; heap.elts[ti].isFree() {
+		t := &heap.elts[ti]
+		ts := heap.eltSize(t)
+		heap.len = t.offset
+		heap.tail = t.prev
+		if t.prev != MaxIndex {
+			heap.elts[t.prev].next = MaxIndex
+		} else {
+			heap.head = MaxIndex
+		}
+		heap.removeFreeElt(ti, ts)
+		released = uint(ts)
+	}
+	return
+}
+
 func (heap *Heap) String() (s string) {
 	s = fmt.Sprintf("%d elts", len(heap.elts))
 	if heap.maxLen != 0 {
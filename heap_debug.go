@@ -115,6 +115,13 @@ func (p *Heap) validate() (err error) {
 		nVisited++
 	}
 
+	for i := range p.free {
+		if got, want := p.freeNonEmpty.Get(uint(i)), len(p.free[i]) > 0; got != want {
+			err = fmt.Errorf("free list %d non-empty bit %v != %v", i, got, want)
+			return
+		}
+	}
+
 	for ei := range p.elts {
 		if visitedElts[ei] == unvisited {
 			err = fmt.Errorf("unvisited elt %d", ei)
@@ -141,6 +148,12 @@ type testHeap struct {
 	validateEvery Count
 	printEvery    Count
 
+	// Compact heap every so many iterations (zero means never).
+	compactEvery Count
+
+	// Allocation policy.
+	policy HeapPolicy
+
 	// Seed to make randomness deterministic.  0 means choose seed.
 	seed int64
 
@@ -165,6 +178,7 @@ func HeapTest() {
 	flag.Var(&t.iterations, "iter", "Number of iterations")
 	flag.Var(&t.validateEvery, "valid", "Number of iterations per validate")
 	flag.Var(&t.printEvery, "print", "Number of iterations per print")
+	flag.Var(&t.compactEvery, "compact", "Number of iterations per compaction")
 	flag.Int64Var(&t.seed, "seed", 0, "Seed for random number generator")
 	flag.IntVar(&t.log2MaxLen, "len", 8, "Log2 max length of object to allocate")
 	flag.Var(&t.nObjects, "objects", "Number of random objects")
@@ -202,6 +216,67 @@ func (t *testHeap) validate(iter int) (err error) {
 	return
 }
 
+// Data for each object identifies object so overlapping objects are detected.
+func (t *testHeap) data(o *randHeapObj, i uint) uint64 {
+	return uint64(o.id)<<32 + uint64(i)
+}
+
+func (t *testHeap) setData(o *randHeapObj, s *Uint64Vec) {
+	s.Validate(o.offset + o.len - 1)
+	for j := uint(0); j < o.len; j++ {
+		(*s)[o.offset+j] = t.data(o, j)
+	}
+}
+
+// Check first n data elements of object at given offset.
+func (t *testHeap) checkData(o *randHeapObj, s Uint64Vec, offset, n uint) (err error) {
+	for j := uint(0); j < n; j++ {
+		if got, want := s[offset+j], t.data(o, j); got != want {
+			err = fmt.Errorf("data mismatch offset %d: 0x%x != 0x%x", o.offset+j, got, want)
+			return
+		}
+	}
+	return
+}
+
+func (t *testHeap) compact(objs randHeapObjVec, s Uint64Vec) (err error) {
+	p := &t.heap
+	byId := make(map[Index]*randHeapObj)
+	for i := range objs {
+		if o := &objs[i]; o.len != 0 {
+			byId[o.id] = o
+		}
+	}
+	p.Compact(func(id Index, oldOffset, newOffset, size uint) {
+		o := byId[id]
+		if o == nil || o.offset != oldOffset || size != uint(p.roundSize(Index(o.len))) || newOffset >= oldOffset {
+			err = fmt.Errorf("bad move %d: %d -> %d size %d", id, oldOffset, newOffset, size)
+			return
+		}
+		copy(s[newOffset:newOffset+o.len], s[oldOffset:oldOffset+o.len])
+		o.offset = newOffset
+	})
+	if err != nil {
+		return
+	}
+	// Free space may only remain before aligned objects.
+	f := p.GetFragmentation()
+	if (t.maxAlign == 0 && f.NFree != 0) || f.NUsed != uint(len(byId)) {
+		err = fmt.Errorf("compact: %s", &f)
+		return
+	}
+	for _, o := range byId {
+		if o.offset&(1<<o.align-1) != 0 {
+			err = fmt.Errorf("compact: id %d offset 0x%x lost alignment 2^%d", o.id, o.offset, o.align)
+			return
+		}
+		if err = t.checkData(o, s, o.offset, o.len); err != nil {
+			return
+		}
+	}
+	return
+}
+
 func runHeapTest(t *testHeap) (err error) {
 	var p *Heap = &t.heap
 	var s Uint64Vec
@@ -210,6 +285,7 @@ func runHeapTest(t *testHeap) (err error) {
 	if t.seed == 0 {
 		t.seed = int64(time.Now().Nanosecond())
 	}
+	p.SetPolicy(t.policy)
 
 	rand.Seed(t.seed)
 	if t.verbose != 0 {
@@ -221,12 +297,33 @@ func runHeapTest(t *testHeap) (err error) {
 	for iter = 0; iter < int(t.iterations); iter++ {
 		o := &objs[rand.Int()%len(objs)]
 		if o.len != 0 {
-			if l := p.Len(o.id); l != o.len {
+			if l := p.Len(o.id); l != uint(p.roundSize(Index(o.len))) {
 				err = fmt.Errorf("len mismatch %d != %d", l, o.len)
 				return
 			}
-			p.Put(o.id)
-			o.len = 0
+			if err = t.checkData(o, s, o.offset, o.len); err != nil {
+				return
+			}
+			if rand.Int()%2 == 0 {
+				p.Put(o.id)
+				o.len = 0
+			} else {
+				l := 1 + uint(rand.Int()&(1<<uint(t.log2MaxLen)-1))
+				id, offset := p.Resize(o.id, l)
+				n := o.len
+				if l < n {
+					n = l
+				}
+				if offset != o.offset {
+					s.Validate(offset + l - 1)
+					copy(s[offset:offset+n], s[o.offset:o.offset+n])
+				}
+				if err = t.checkData(o, s, offset, n); err != nil {
+					return
+				}
+				o.id, o.offset, o.len, o.align = id, offset, l, 0
+				t.setData(o, &s)
+			}
 		} else {
 			o.len = 1 + uint(rand.Int()&(1<<uint(t.log2MaxLen)-1))
 			o.align = 0
@@ -243,9 +340,11 @@ func runHeapTest(t *testHeap) (err error) {
 			if o.offset&((1<<o.align)-1) != 0 {
 				panic("unaligned")
 			}
-			s.Validate(o.offset + o.len - 1)
-			for j := uint(0); j < o.len; j++ {
-				s[o.offset+j] = uint64(o.id)<<uint(t.log2MaxLen) + uint64(o.offset+j)
+			t.setData(o, &s)
+		}
+		if t.compactEvery != 0 && iter%int(t.compactEvery) == 0 {
+			if err = t.compact(objs, s); err != nil {
+				return
 			}
 		}
 		err = t.validate(iter)
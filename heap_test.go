@@ -5,6 +5,7 @@
 package elib
 
 import (
+	"math/rand"
 	"testing"
 )
 
@@ -19,3 +20,106 @@ func TestHeap(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestHeapValidate(t *testing.T) {
+	for _, policy := range []HeapPolicy{HeapFirstFit, HeapBestFit, HeapSizeClass} {
+		c := testHeap{
+			iterations:    10000,
+			nObjects:      50,
+			log2MaxLen:    6,
+			maxAlign:      4,
+			validateEvery: 1,
+			compactEvery:  1000,
+			policy:        policy,
+		}
+		err := runHeapTest(&c)
+		if err != nil {
+			t.Errorf("%s: %v", policy, err)
+		}
+	}
+}
+
+// Random alloc/free workload; reports heap length relative to peak used size.
+func benchmarkHeap(b *testing.B, policy HeapPolicy) {
+	var h Heap
+	h.SetPolicy(policy)
+	rand.Seed(1)
+	ids := make([]Index, 1000)
+	used := make([]bool, len(ids))
+	maxUsed := uint64(0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		j := rand.Intn(len(ids))
+		if used[j] {
+			h.Put(ids[j])
+		} else {
+			ids[j], _ = h.Get(1 + uint(rand.Intn(256)))
+		}
+		used[j] = !used[j]
+		if i%1024 == 0 {
+			if u := h.GetUsage().Used; u > maxUsed {
+				maxUsed = u
+			}
+		}
+	}
+	if maxUsed > 0 {
+		b.ReportMetric(float64(h.len)/float64(maxUsed), "len/used")
+	}
+}
+
+func BenchmarkHeapFirstFit(b *testing.B)  { benchmarkHeap(b, HeapFirstFit) }
+func BenchmarkHeapBestFit(b *testing.B)   { benchmarkHeap(b, HeapBestFit) }
+func BenchmarkHeapSizeClass(b *testing.B) { benchmarkHeap(b, HeapSizeClass) }
+
+// Compact must keep alignment of elements allocated by GetAligned.
+func TestHeapCompactAligned(t *testing.T) {
+	var h Heap
+	rand.Seed(1)
+	type alloc struct {
+		id        Index
+		offset    uint
+		log2Align uint
+	}
+	var live []alloc
+	for i := 0; i < 1000; i++ {
+		if len(live) > 0 && rand.Intn(3) == 0 {
+			j := rand.Intn(len(live))
+			h.Put(live[j].id)
+			live[j] = live[len(live)-1]
+			live = live[:len(live)-1]
+			continue
+		}
+		a := alloc{log2Align: uint(rand.Intn(7))}
+		a.id, a.offset = h.GetAligned(1+uint(rand.Intn(40)), a.log2Align)
+		live = append(live, a)
+	}
+	moves := 0
+	h.Compact(func(id Index, oldOffset, newOffset, size uint) {
+		moves++
+		for i := range live {
+			if live[i].id == id {
+				if live[i].offset != oldOffset {
+					t.Fatalf("id %d: moved from %d expected %d", id, oldOffset, live[i].offset)
+				}
+				live[i].offset = newOffset
+			}
+		}
+	})
+	if moves == 0 {
+		t.Fatal("nothing moved")
+	}
+	for _, a := range live {
+		o, _ := h.GetID(a.id)
+		if uint(o) != a.offset {
+			t.Errorf("id %d: offset %d != moved offset %d", a.id, o, a.offset)
+		}
+		if o&(1<<a.log2Align-1) != 0 {
+			t.Errorf("id %d: offset 0x%x not aligned to 2^%d", a.id, o, a.log2Align)
+		}
+	}
+	if Debug {
+		if err := h.validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
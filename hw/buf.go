@@ -7,6 +7,7 @@ package hw
 import (
 	"github.com/platinasystems/elib"
 	"github.com/platinasystems/elib/cpu"
+	"github.com/platinasystems/elib/parse"
 
 	"fmt"
 	"math"
@@ -29,6 +30,10 @@ var bufferFlagStrings = [...]string{
 
 func (f BufferFlag) String() string { return elib.FlagStringer(bufferFlagStrings[:], elib.Word(f)) }
 
+var bufferFlagMap = parse.NewFlagStringerMap(bufferFlagStrings[:])
+
+func (f *BufferFlag) Parse(in *parse.Input) { bufferFlagMap.ParseWithArgs(in, &parse.Args{f}) }
+
 type RefHeader struct {
 	// 28 bits of offset; 4 bits of flags.
 	offsetAndFlags uint32
@@ -229,6 +234,10 @@ var bufferStateStrings = [...]string{
 
 func (s BufferState) String() string { return elib.Stringer(bufferStateStrings[:], int(s)) }
 
+var bufferStateMap = parse.NewStringerMap(bufferStateStrings[:])
+
+func (s *BufferState) Parse(in *parse.Input) { bufferStateMap.ParseWithArgs(in, &parse.Args{s}) }
+
 var trackBufferState = elib.Debug
 
 func (p *BufferPool) setState(offset uint32, new BufferState) (old BufferState) {
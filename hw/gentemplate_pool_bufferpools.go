@@ -16,6 +16,9 @@ type bufferPools struct {
 	elts []*BufferPool
 }
 
+// Growth policy and statistics for bufferPools.
+var bufferPoolsGrowth = elib.NewGrowth("hw.bufferPools", (**BufferPool)(nil))
+
 func (p *bufferPools) GetIndex() (i uint) {
 	l := uint(len(p.elts))
 	i = p.Pool.GetIndex(l)
@@ -41,7 +44,7 @@ func (p *bufferPools) Resize(n uint) {
 	c := elib.Index(cap(p.elts))
 	l := elib.Index(len(p.elts) + int(n))
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = bufferPoolsGrowth.NextCap(elib.Index(len(p.elts)), c, l)
 		q := make([]*BufferPool, l, c)
 		copy(q, p.elts)
 		p.elts = q
@@ -53,7 +56,7 @@ func (p *bufferPools) Validate(i uint) {
 	c := elib.Index(cap(p.elts))
 	l := elib.Index(i) + 1
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = bufferPoolsGrowth.NextCap(elib.Index(len(p.elts)), c, l)
 		q := make([]*BufferPool, l, c)
 		copy(q, p.elts)
 		p.elts = q
@@ -86,3 +89,45 @@ func (p *bufferPools) ForeachIndex(f func(i uint)) {
 		}
 	}
 }
+
+// SetConcurrent puts pool in concurrent mode with fixed capacity of maxLen elements.
+// Data is allocated up front so that elements do not move.  Each thread allocates via its own cache
+// (see Pool.NewCache) and must only access elements it has allocated.
+func (p *bufferPools) SetConcurrent(batch, maxLen uint) {
+	p.Pool.SetConcurrent(batch, maxLen)
+	p.Validate(maxLen - 1)
+}
+
+// GetHandle allocates new element returning its index and handle.
+func (p *bufferPools) GetHandle() (i uint, h elib.PoolHandle) {
+	i = p.GetIndex()
+	h = p.Handle(i)
+	return
+}
+
+// ValidateHandle returns index for handle or error if handle is stale.
+func (p *bufferPools) ValidateHandle(h elib.PoolHandle) (i uint, err error) {
+	if i = h.Index(); i >= uint(len(p.elts)) {
+		err = elib.ErrPoolStaleHandle
+		return
+	}
+	return p.Pool.ValidateHandle(h)
+}
+
+// PutHandle frees element for handle.  Stale handles are not freed.
+func (p *bufferPools) PutHandle(h elib.PoolHandle) (err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		p.PutIndex(i)
+	}
+	return
+}
+
+// GetByHandle returns pointer to element for handle or error if handle is stale.
+func (p *bufferPools) GetByHandle(h elib.PoolHandle) (x **BufferPool, err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		x = &p.elts[i]
+	}
+	return
+}
@@ -13,11 +13,14 @@ import (
 
 type RefVec []Ref
 
+// Growth policy and statistics for RefVec.
+var RefVecGrowth = elib.NewGrowth("hw.RefVec", (*Ref)(nil))
+
 func (p *RefVec) Resize(n uint) {
 	c := elib.Index(cap(*p))
 	l := elib.Index(len(*p)) + elib.Index(n)
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = RefVecGrowth.NextCap(elib.Index(len(*p)), c, l)
 		q := make([]Ref, l, c)
 		copy(q, *p)
 		*p = q
@@ -42,7 +45,7 @@ func (p *RefVec) validate(new_len uint, zero *Ref) *Ref {
 func (p *RefVec) validateSlowPath(zero *Ref,
 	c, l, lʹ elib.Index) *Ref {
 	if l > c {
-		cNext := elib.NextResizeCap(l)
+		cNext := RefVecGrowth.NextCap(lʹ, c, l)
 		q := make([]Ref, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
@@ -7,6 +7,7 @@ package hw
 
 import (
 	"fmt"
+	"math/bits"
 	"syscall"
 	"unsafe"
 )
@@ -34,6 +35,8 @@ func CheckRegAddr(name string, got, want uint) {
 }
 
 // Memory-mapped read/write
+func LoadUint16(addr *uint16) (data uint16)
+func StoreUint16(addr *uint16, data uint16)
 func LoadUint32(addr *uint32) (data uint32)
 func StoreUint32(addr *uint32, data uint32)
 func LoadUint64(addr *uint64) (data uint64)
@@ -51,5 +54,13 @@ func (r *Reg8) Offset() uint  { return uint(uintptr(unsafe.Pointer(r)) - RegsBas
 func (r *Reg16) Offset() uint { return uint(uintptr(unsafe.Pointer(r)) - RegsBaseAddress) }
 func (r *Reg32) Offset() uint { return uint(uintptr(unsafe.Pointer(r)) - RegsBaseAddress) }
 
+func (r *Reg16) Get() uint16  { return LoadUint16((*uint16)(r)) }
+func (r *Reg16) Set(x uint16) { StoreUint16((*uint16)(r), x) }
 func (r *Reg32) Get() uint32  { return LoadUint32((*uint32)(r)) }
 func (r *Reg32) Set(x uint32) { StoreUint32((*uint32)(r), x) }
+
+// Byte swapped access for registers with byte order opposite to CPU (e.g. big endian registers on x86).
+func (r *Reg16) GetSwap() uint16  { return bits.ReverseBytes16(r.Get()) }
+func (r *Reg16) SetSwap(x uint16) { r.Set(bits.ReverseBytes16(x)) }
+func (r *Reg32) GetSwap() uint32  { return bits.ReverseBytes32(r.Get()) }
+func (r *Reg32) SetSwap(x uint32) { r.Set(bits.ReverseBytes32(x)) }
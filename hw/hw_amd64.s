@@ -1,3 +1,17 @@
+// func LoadUint16(addr *uint16) (data uint16)
+TEXT ·LoadUint16(SB),4,$0-10
+	MOVQ	addr+0(FP), AX
+	MOVW	0(AX), AX
+	MOVW	AX, data+8(FP)
+	RET
+
+// func StoreUint16(addr *uint16, data uint16)
+TEXT ·StoreUint16(SB),4,$0-10
+	MOVQ	addr+0(FP), AX
+	MOVW	data+8(FP), BX
+	MOVW	BX, 0(AX)
+	RET
+
 // func LoadUint32(addr *uint32) (data uint32)
 TEXT ·LoadUint32(SB),4,$0-12
 	MOVQ	addr+0(FP), AX
@@ -1,3 +1,17 @@
+// func LoadUint16(addr *uint16) (data uint16)
+TEXT ·LoadUint16(SB),4,$0-6
+	MOVW	addr+0(FP), R1
+	MOVHU	0(R1), R0
+	MOVH	R0, data+4(FP)
+	RET
+
+// func StoreUint16(addr *uint16, data uint16)
+TEXT ·StoreUint16(SB),4,$0-6
+	MOVW	addr+0(FP), R1
+	MOVHU	data+4(FP), R0
+	MOVH	R0, 0(R1)
+	RET
+
 // func LoadUint32(addr *uint32) (data uint32)
 TEXT ·LoadUint32(SB),4,$0-8
 	MOVW	addr+0(FP), R1
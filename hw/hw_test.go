@@ -0,0 +1,28 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hw
+
+import (
+	"testing"
+)
+
+func TestRegSwap(t *testing.T) {
+	var (
+		r16 Reg16
+		r32 Reg32
+	)
+	r16.SetSwap(0x1122)
+	r32.SetSwap(0x11223344)
+	if r16 != 0x2211 || r32 != 0x44332211 {
+		t.Errorf("set swap: %x %x", r16, r32)
+	}
+	if x, y := r16.GetSwap(), r32.GetSwap(); x != 0x1122 || y != 0x11223344 {
+		t.Errorf("get swap: %x %x", x, y)
+	}
+	r16.Set(0xabcd)
+	if r16.Get() != 0xabcd {
+		t.Errorf("reg16 get: %x", r16.Get())
+	}
+}
@@ -19,3 +19,4 @@ func DmaGetData(id elib.Index) (b []byte)                         { return heap.
 func DmaGetPointer(o uint) unsafe.Pointer                         { return heap.Data(o) }
 func DmaIsValidOffset(o uint) bool                                { return heap.OffsetValid(o) }
 func DmaHeapUsage() string                                        { return heap.String() }
+func DmaHeapFragmentation() elib.HeapFragmentation                { return heap.Fragmentation() }
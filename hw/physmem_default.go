@@ -14,3 +14,12 @@ func DmaAllocAligned(n, log2Align uint) (b []byte, id elib.Index, offset, cap ui
 	return heap.GetAligned(n, log2Align)
 }
 func DmaPhysAddress(a uintptr) uintptr { return a }
+
+// DmaCompact moves DMA allocations towards start of heap calling moved so caller can update
+// references (including any physical addresses given to hardware).
+// Not available with uio_pci_dma since moves may cross page boundaries.
+// Aligned allocations (e.g. page aligned rings) keep their alignment.  Returns number of bytes
+// released from end of heap; released pages are given back to the OS (see elib.MemHeap.Compact).
+func DmaCompact(moved func(id elib.Index, oldOffset, newOffset uint)) (released uint) {
+	return heap.Compact(moved)
+}
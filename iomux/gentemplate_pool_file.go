@@ -16,6 +16,9 @@ type filePool struct {
 	files []Filer
 }
 
+// Growth policy and statistics for filePool.
+var filePoolGrowth = elib.NewGrowth("iomux.filePool", (*Filer)(nil))
+
 func (p *filePool) GetIndex() (i uint) {
 	l := uint(len(p.files))
 	i = p.Pool.GetIndex(l)
@@ -41,7 +44,7 @@ func (p *filePool) Resize(n uint) {
 	c := elib.Index(cap(p.files))
 	l := elib.Index(len(p.files) + int(n))
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = filePoolGrowth.NextCap(elib.Index(len(p.files)), c, l)
 		q := make([]Filer, l, c)
 		copy(q, p.files)
 		p.files = q
@@ -53,7 +56,7 @@ func (p *filePool) Validate(i uint) {
 	c := elib.Index(cap(p.files))
 	l := elib.Index(i) + 1
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = filePoolGrowth.NextCap(elib.Index(len(p.files)), c, l)
 		q := make([]Filer, l, c)
 		copy(q, p.files)
 		p.files = q
@@ -86,3 +89,45 @@ func (p *filePool) ForeachIndex(f func(i uint)) {
 		}
 	}
 }
+
+// SetConcurrent puts pool in concurrent mode with fixed capacity of maxLen elements.
+// Data is allocated up front so that elements do not move.  Each thread allocates via its own cache
+// (see Pool.NewCache) and must only access elements it has allocated.
+func (p *filePool) SetConcurrent(batch, maxLen uint) {
+	p.Pool.SetConcurrent(batch, maxLen)
+	p.Validate(maxLen - 1)
+}
+
+// GetHandle allocates new element returning its index and handle.
+func (p *filePool) GetHandle() (i uint, h elib.PoolHandle) {
+	i = p.GetIndex()
+	h = p.Handle(i)
+	return
+}
+
+// ValidateHandle returns index for handle or error if handle is stale.
+func (p *filePool) ValidateHandle(h elib.PoolHandle) (i uint, err error) {
+	if i = h.Index(); i >= uint(len(p.files)) {
+		err = elib.ErrPoolStaleHandle
+		return
+	}
+	return p.Pool.ValidateHandle(h)
+}
+
+// PutHandle frees element for handle.  Stale handles are not freed.
+func (p *filePool) PutHandle(h elib.PoolHandle) (err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		p.PutIndex(i)
+	}
+	return
+}
+
+// GetByHandle returns pointer to element for handle or error if handle is stale.
+func (p *filePool) GetByHandle(h elib.PoolHandle) (x *Filer, err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		x = &p.files[i]
+	}
+	return
+}
@@ -14,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -70,6 +71,28 @@ func (ns rtNodes) Swap(i, j int)      { ns[i], ns[j] = ns[j], ns[i] }
 func (ns rtNodes) Len() int           { return len(ns) }
 
 func (l *Loop) showRuntimeStats(c cli.Commander, w cli.Writer, in *cli.Input) (err error) {
+	var (
+		format        elib.TableFormat
+		sortCol, cols string
+		reverse       bool
+	)
+	for !in.End() {
+		var s string
+		switch {
+		case in.Parse("f%*ormat %s", &s):
+			if err = format.Set(s); err != nil {
+				return
+			}
+		case in.Parse("s%*ort %s", &sortCol):
+		case in.Parse("r%*everse"):
+			reverse = true
+		case in.Parse("c%*olumns %s", &cols):
+		default:
+			err = cli.ParseError
+			return
+		}
+	}
+
 	ns := rtNodes{}
 	for i := range l.DataNodes {
 		n := l.DataNodes[i].GetNode()
@@ -109,7 +132,7 @@ func (l *Loop) showRuntimeStats(c cli.Commander, w cli.Writer, in *cli.Input) (e
 		l.activePollerPool.Foreach(func(a *activePoller) {
 			s.add(&a.pollerStats)
 		})
-		if s.calls > 0 {
+		if s.calls > 0 && format == elib.TableText {
 			dt := time.Since(l.timeLastRuntimeClear).Seconds()
 			vecsPerSec := float64(s.vectors) / dt
 			clocksPerVec := float64(s.clocks) / float64(s.vectors)
@@ -120,7 +143,52 @@ func (l *Loop) showRuntimeStats(c cli.Commander, w cli.Writer, in *cli.Input) (e
 	}
 
 	sort.Sort(ns)
-	elib.TabulateWrite(w, ns)
+	tab := elib.Tabulate(ns)
+	if sortCol != "" {
+		if err = tab.Sort(sortCol, reverse); err != nil {
+			return
+		}
+	}
+	if cols != "" {
+		if err = tab.Select(strings.Split(cols, ",")...); err != nil {
+			return
+		}
+	}
+	err = tab.WriteFormat(w, format)
+	return
+}
+
+// Show totals of capacity allocated by growth of vectors, pools and heaps since start.
+// Totals are cumulative since freed vectors are not seen: wasted is percent of allocated bytes
+// beyond requested length at time of each resize, not capacity currently unused.
+func (l *Loop) showMemory(c cli.Commander, w cli.Writer, in *cli.Input) (err error) {
+	var (
+		format  elib.TableFormat
+		sortCol string
+		reverse bool
+	)
+	for !in.End() {
+		var s string
+		switch {
+		case in.Parse("f%*ormat %s", &s):
+			if err = format.Set(s); err != nil {
+				return
+			}
+		case in.Parse("s%*ort %s", &sortCol):
+		case in.Parse("r%*everse"):
+			reverse = true
+		default:
+			err = cli.ParseError
+			return
+		}
+	}
+	tab := elib.GrowthReport()
+	if sortCol != "" {
+		if err = tab.Sort(sortCol, reverse); err != nil {
+			return
+		}
+	}
+	err = tab.WriteFormat(w, format)
 	return
 }
 
@@ -206,6 +274,7 @@ func (l *Loop) cliInit() {
 	c.AddCommand(&cli.Command{
 		Name:      "show runtime",
 		ShortHelp: "show main loop runtime statistics",
+		Help:      "show runtime [format text|csv|json|markdown] [sort COLUMN [reverse]] [columns COL,...]",
 		Action:    l.showRuntimeStats,
 	})
 	c.AddCommand(&cli.Command{
@@ -213,6 +282,12 @@ func (l *Loop) cliInit() {
 		ShortHelp: "clear main loop runtime statistics",
 		Action:    l.clearRuntimeStats,
 	})
+	c.AddCommand(&cli.Command{
+		Name:      "show memory",
+		ShortHelp: "show cumulative memory allocated by vector, pool and heap growth",
+		Help:      "show memory [format text|csv|json|markdown] [sort COLUMN [reverse]]",
+		Action:    l.showMemory,
+	})
 	c.AddCommand(&cli.Command{
 		Name:      "show event-log",
 		ShortHelp: "show events in event log",
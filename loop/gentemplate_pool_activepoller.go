@@ -16,6 +16,9 @@ type activePollerPool struct {
 	entries []*activePoller
 }
 
+// Growth policy and statistics for activePollerPool.
+var activePollerPoolGrowth = elib.NewGrowth("loop.activePollerPool", (**activePoller)(nil))
+
 func (p *activePollerPool) GetIndex() (i uint) {
 	l := uint(len(p.entries))
 	i = p.Pool.GetIndex(l)
@@ -41,7 +44,7 @@ func (p *activePollerPool) Resize(n uint) {
 	c := elib.Index(cap(p.entries))
 	l := elib.Index(len(p.entries) + int(n))
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = activePollerPoolGrowth.NextCap(elib.Index(len(p.entries)), c, l)
 		q := make([]*activePoller, l, c)
 		copy(q, p.entries)
 		p.entries = q
@@ -53,7 +56,7 @@ func (p *activePollerPool) Validate(i uint) {
 	c := elib.Index(cap(p.entries))
 	l := elib.Index(i) + 1
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = activePollerPoolGrowth.NextCap(elib.Index(len(p.entries)), c, l)
 		q := make([]*activePoller, l, c)
 		copy(q, p.entries)
 		p.entries = q
@@ -86,3 +89,45 @@ func (p *activePollerPool) ForeachIndex(f func(i uint)) {
 		}
 	}
 }
+
+// SetConcurrent puts pool in concurrent mode with fixed capacity of maxLen elements.
+// Data is allocated up front so that elements do not move.  Each thread allocates via its own cache
+// (see Pool.NewCache) and must only access elements it has allocated.
+func (p *activePollerPool) SetConcurrent(batch, maxLen uint) {
+	p.Pool.SetConcurrent(batch, maxLen)
+	p.Validate(maxLen - 1)
+}
+
+// GetHandle allocates new element returning its index and handle.
+func (p *activePollerPool) GetHandle() (i uint, h elib.PoolHandle) {
+	i = p.GetIndex()
+	h = p.Handle(i)
+	return
+}
+
+// ValidateHandle returns index for handle or error if handle is stale.
+func (p *activePollerPool) ValidateHandle(h elib.PoolHandle) (i uint, err error) {
+	if i = h.Index(); i >= uint(len(p.entries)) {
+		err = elib.ErrPoolStaleHandle
+		return
+	}
+	return p.Pool.ValidateHandle(h)
+}
+
+// PutHandle frees element for handle.  Stale handles are not freed.
+func (p *activePollerPool) PutHandle(h elib.PoolHandle) (err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		p.PutIndex(i)
+	}
+	return
+}
+
+// GetByHandle returns pointer to element for handle or error if handle is stale.
+func (p *activePollerPool) GetByHandle(h elib.PoolHandle) (x **activePoller, err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		x = &p.entries[i]
+	}
+	return
+}
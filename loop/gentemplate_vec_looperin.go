@@ -13,11 +13,14 @@ import (
 
 type looperInVec []LooperIn
 
+// Growth policy and statistics for looperInVec.
+var looperInVecGrowth = elib.NewGrowth("loop.looperInVec", (*LooperIn)(nil))
+
 func (p *looperInVec) Resize(n uint) {
 	c := elib.Index(cap(*p))
 	l := elib.Index(len(*p)) + elib.Index(n)
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = looperInVecGrowth.NextCap(elib.Index(len(*p)), c, l)
 		q := make([]LooperIn, l, c)
 		copy(q, *p)
 		*p = q
@@ -42,7 +45,7 @@ func (p *looperInVec) validate(new_len uint, zero *LooperIn) *LooperIn {
 func (p *looperInVec) validateSlowPath(zero *LooperIn,
 	c, l, lʹ elib.Index) *LooperIn {
 	if l > c {
-		cNext := elib.NextResizeCap(l)
+		cNext := looperInVecGrowth.NextCap(lʹ, c, l)
 		q := make([]LooperIn, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
@@ -13,11 +13,14 @@ import (
 
 type nextNodeVec []nextNode
 
+// Growth policy and statistics for nextNodeVec.
+var nextNodeVecGrowth = elib.NewGrowth("loop.nextNodeVec", (*nextNode)(nil))
+
 func (p *nextNodeVec) Resize(n uint) {
 	c := elib.Index(cap(*p))
 	l := elib.Index(len(*p)) + elib.Index(n)
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = nextNodeVecGrowth.NextCap(elib.Index(len(*p)), c, l)
 		q := make([]nextNode, l, c)
 		copy(q, *p)
 		*p = q
@@ -42,7 +45,7 @@ func (p *nextNodeVec) validate(new_len uint, zero *nextNode) *nextNode {
 func (p *nextNodeVec) validateSlowPath(zero *nextNode,
 	c, l, lʹ elib.Index) *nextNode {
 	if l > c {
-		cNext := elib.NextResizeCap(l)
+		cNext := nextNodeVecGrowth.NextCap(lʹ, c, l)
 		q := make([]nextNode, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
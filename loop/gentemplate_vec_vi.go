@@ -13,11 +13,14 @@ import (
 
 type viVec []Vi
 
+// Growth policy and statistics for viVec.
+var viVecGrowth = elib.NewGrowth("loop.viVec", (*Vi)(nil))
+
 func (p *viVec) Resize(n uint) {
 	c := elib.Index(cap(*p))
 	l := elib.Index(len(*p)) + elib.Index(n)
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = viVecGrowth.NextCap(elib.Index(len(*p)), c, l)
 		q := make([]Vi, l, c)
 		copy(q, *p)
 		*p = q
@@ -42,7 +45,7 @@ func (p *viVec) validate(new_len uint, zero *Vi) *Vi {
 func (p *viVec) validateSlowPath(zero *Vi,
 	c, l, lʹ elib.Index) *Vi {
 	if l > c {
-		cNext := elib.NextResizeCap(l)
+		cNext := viVecGrowth.NextCap(lʹ, c, l)
 		q := make([]Vi, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
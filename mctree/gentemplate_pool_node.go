@@ -16,6 +16,9 @@ type node_pool struct {
 	nodes []node
 }
 
+// Growth policy and statistics for node_pool.
+var node_poolGrowth = elib.NewGrowth("mctree.node_pool", (*node)(nil))
+
 func (p *node_pool) GetIndex() (i uint) {
 	l := uint(len(p.nodes))
 	i = p.Pool.GetIndex(l)
@@ -41,7 +44,7 @@ func (p *node_pool) Resize(n uint) {
 	c := elib.Index(cap(p.nodes))
 	l := elib.Index(len(p.nodes) + int(n))
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = node_poolGrowth.NextCap(elib.Index(len(p.nodes)), c, l)
 		q := make([]node, l, c)
 		copy(q, p.nodes)
 		p.nodes = q
@@ -53,7 +56,7 @@ func (p *node_pool) Validate(i uint) {
 	c := elib.Index(cap(p.nodes))
 	l := elib.Index(i) + 1
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = node_poolGrowth.NextCap(elib.Index(len(p.nodes)), c, l)
 		q := make([]node, l, c)
 		copy(q, p.nodes)
 		p.nodes = q
@@ -86,3 +89,45 @@ func (p *node_pool) ForeachIndex(f func(i uint)) {
 		}
 	}
 }
+
+// SetConcurrent puts pool in concurrent mode with fixed capacity of maxLen elements.
+// Data is allocated up front so that elements do not move.  Each thread allocates via its own cache
+// (see Pool.NewCache) and must only access elements it has allocated.
+func (p *node_pool) SetConcurrent(batch, maxLen uint) {
+	p.Pool.SetConcurrent(batch, maxLen)
+	p.Validate(maxLen - 1)
+}
+
+// GetHandle allocates new element returning its index and handle.
+func (p *node_pool) GetHandle() (i uint, h elib.PoolHandle) {
+	i = p.GetIndex()
+	h = p.Handle(i)
+	return
+}
+
+// ValidateHandle returns index for handle or error if handle is stale.
+func (p *node_pool) ValidateHandle(h elib.PoolHandle) (i uint, err error) {
+	if i = h.Index(); i >= uint(len(p.nodes)) {
+		err = elib.ErrPoolStaleHandle
+		return
+	}
+	return p.Pool.ValidateHandle(h)
+}
+
+// PutHandle frees element for handle.  Stale handles are not freed.
+func (p *node_pool) PutHandle(h elib.PoolHandle) (err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		p.PutIndex(i)
+	}
+	return
+}
+
+// GetByHandle returns pointer to element for handle or error if handle is stale.
+func (p *node_pool) GetByHandle(h elib.PoolHandle) (x *node, err error) {
+	var i uint
+	if i, err = p.ValidateHandle(h); err == nil {
+		x = &p.nodes[i]
+	}
+	return
+}
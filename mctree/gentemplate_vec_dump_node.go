@@ -13,11 +13,14 @@ import (
 
 type dump_node_vec []dump_node
 
+// Growth policy and statistics for dump_node_vec.
+var dump_node_vecGrowth = elib.NewGrowth("mctree.dump_node_vec", (*dump_node)(nil))
+
 func (p *dump_node_vec) Resize(n uint) {
 	c := elib.Index(cap(*p))
 	l := elib.Index(len(*p)) + elib.Index(n)
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = dump_node_vecGrowth.NextCap(elib.Index(len(*p)), c, l)
 		q := make([]dump_node, l, c)
 		copy(q, *p)
 		*p = q
@@ -42,7 +45,7 @@ func (p *dump_node_vec) validate(new_len uint, zero *dump_node) *dump_node {
 func (p *dump_node_vec) validateSlowPath(zero *dump_node,
 	c, l, lʹ elib.Index) *dump_node {
 	if l > c {
-		cNext := elib.NextResizeCap(l)
+		cNext := dump_node_vecGrowth.NextCap(lʹ, c, l)
 		q := make([]dump_node, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
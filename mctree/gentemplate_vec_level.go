@@ -13,11 +13,14 @@ import (
 
 type level_vec []level
 
+// Growth policy and statistics for level_vec.
+var level_vecGrowth = elib.NewGrowth("mctree.level_vec", (*level)(nil))
+
 func (p *level_vec) Resize(n uint) {
 	c := elib.Index(cap(*p))
 	l := elib.Index(len(*p)) + elib.Index(n)
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = level_vecGrowth.NextCap(elib.Index(len(*p)), c, l)
 		q := make([]level, l, c)
 		copy(q, *p)
 		*p = q
@@ -42,7 +45,7 @@ func (p *level_vec) validate(new_len uint, zero *level) *level {
 func (p *level_vec) validateSlowPath(zero *level,
 	c, l, lʹ elib.Index) *level {
 	if l > c {
-		cNext := elib.NextResizeCap(l)
+		cNext := level_vecGrowth.NextCap(lʹ, c, l)
 		q := make([]level, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
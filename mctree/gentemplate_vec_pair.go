@@ -13,11 +13,14 @@ import (
 
 type pair_vec []Pair
 
+// Growth policy and statistics for pair_vec.
+var pair_vecGrowth = elib.NewGrowth("mctree.pair_vec", (*Pair)(nil))
+
 func (p *pair_vec) Resize(n uint) {
 	c := elib.Index(cap(*p))
 	l := elib.Index(len(*p)) + elib.Index(n)
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = pair_vecGrowth.NextCap(elib.Index(len(*p)), c, l)
 		q := make([]Pair, l, c)
 		copy(q, *p)
 		*p = q
@@ -42,7 +45,7 @@ func (p *pair_vec) validate(new_len uint, zero *Pair) *Pair {
 func (p *pair_vec) validateSlowPath(zero *Pair,
 	c, l, lʹ elib.Index) *Pair {
 	if l > c {
-		cNext := elib.NextResizeCap(l)
+		cNext := pair_vecGrowth.NextCap(lʹ, c, l)
 		q := make([]Pair, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
@@ -13,11 +13,14 @@ import (
 
 type pair_offset_vec []pair_offset
 
+// Growth policy and statistics for pair_offset_vec.
+var pair_offset_vecGrowth = elib.NewGrowth("mctree.pair_offset_vec", (*pair_offset)(nil))
+
 func (p *pair_offset_vec) Resize(n uint) {
 	c := elib.Index(cap(*p))
 	l := elib.Index(len(*p)) + elib.Index(n)
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = pair_offset_vecGrowth.NextCap(elib.Index(len(*p)), c, l)
 		q := make([]pair_offset, l, c)
 		copy(q, *p)
 		*p = q
@@ -42,7 +45,7 @@ func (p *pair_offset_vec) validate(new_len uint, zero *pair_offset) *pair_offset
 func (p *pair_offset_vec) validateSlowPath(zero *pair_offset,
 	c, l, lʹ elib.Index) *pair_offset {
 	if l > c {
-		cNext := elib.NextResizeCap(l)
+		cNext := pair_offset_vecGrowth.NextCap(lʹ, c, l)
 		q := make([]pair_offset, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
@@ -6,13 +6,14 @@ package elib
 
 import (
 	"fmt"
+	"math/bits"
 	"runtime"
 	"unsafe"
 )
 
 const (
 	supportsUnaligned = runtime.GOARCH == "386" || runtime.GOARCH == "amd64" || runtime.GOARCH == "ppc64" || runtime.GOARCH == "ppc64le" || runtime.GOARCH == "s390x"
-	isBig             = runtime.GOARCH == "ppc64" || runtime.GOARCH == "mips64"
+	isBig             = runtime.GOARCH == "ppc64" || runtime.GOARCH == "mips64" || runtime.GOARCH == "mips" || runtime.GOARCH == "s390x"
 )
 
 func _ua16(p unsafe.Pointer, i, j uint) uint16 {
@@ -68,6 +69,193 @@ func UnalignedUint64(p unsafe.Pointer, i uintptr) uint64 {
 	}
 }
 
+// Stores in host byte order.
+func StoreUnalignedUint16(p unsafe.Pointer, i uintptr, x uint16) {
+	if isBig {
+		StoreUint16BE(p, i, x)
+	} else {
+		StoreUint16LE(p, i, x)
+	}
+}
+
+func StoreUnalignedUint32(p unsafe.Pointer, i uintptr, x uint32) {
+	if isBig {
+		StoreUint32BE(p, i, x)
+	} else {
+		StoreUint32LE(p, i, x)
+	}
+}
+
+func StoreUnalignedUint64(p unsafe.Pointer, i uintptr, x uint64) {
+	if isBig {
+		StoreUint64BE(p, i, x)
+	} else {
+		StoreUint64LE(p, i, x)
+	}
+}
+
+// Big (network order) and little endian loads and stores at arbitrary byte offsets.
+// Architectures supporting unaligned access use a single (possibly byte swapped) load or store.
+func LoadUint16LE(p unsafe.Pointer, i uintptr) uint16 {
+	p = PointerAdd(p, i)
+	if supportsUnaligned {
+		x := *(*uint16)(p)
+		if isBig {
+			x = bits.ReverseBytes16(x)
+		}
+		return x
+	}
+	q := (*[2]byte)(p)
+	return uint16(q[0]) | uint16(q[1])<<8
+}
+
+func LoadUint16BE(p unsafe.Pointer, i uintptr) uint16 {
+	p = PointerAdd(p, i)
+	if supportsUnaligned {
+		x := *(*uint16)(p)
+		if !isBig {
+			x = bits.ReverseBytes16(x)
+		}
+		return x
+	}
+	q := (*[2]byte)(p)
+	return uint16(q[1]) | uint16(q[0])<<8
+}
+
+func StoreUint16LE(p unsafe.Pointer, i uintptr, x uint16) {
+	p = PointerAdd(p, i)
+	if supportsUnaligned {
+		if isBig {
+			x = bits.ReverseBytes16(x)
+		}
+		*(*uint16)(p) = x
+		return
+	}
+	q := (*[2]byte)(p)
+	q[0], q[1] = byte(x), byte(x>>8)
+}
+
+func StoreUint16BE(p unsafe.Pointer, i uintptr, x uint16) {
+	p = PointerAdd(p, i)
+	if supportsUnaligned {
+		if !isBig {
+			x = bits.ReverseBytes16(x)
+		}
+		*(*uint16)(p) = x
+		return
+	}
+	q := (*[2]byte)(p)
+	q[0], q[1] = byte(x>>8), byte(x)
+}
+
+func LoadUint32LE(p unsafe.Pointer, i uintptr) uint32 {
+	p = PointerAdd(p, i)
+	if supportsUnaligned {
+		x := *(*uint32)(p)
+		if isBig {
+			x = bits.ReverseBytes32(x)
+		}
+		return x
+	}
+	q := (*[4]byte)(p)
+	return uint32(q[0]) | uint32(q[1])<<8 | uint32(q[2])<<16 | uint32(q[3])<<24
+}
+
+func LoadUint32BE(p unsafe.Pointer, i uintptr) uint32 {
+	p = PointerAdd(p, i)
+	if supportsUnaligned {
+		x := *(*uint32)(p)
+		if !isBig {
+			x = bits.ReverseBytes32(x)
+		}
+		return x
+	}
+	q := (*[4]byte)(p)
+	return uint32(q[3]) | uint32(q[2])<<8 | uint32(q[1])<<16 | uint32(q[0])<<24
+}
+
+func StoreUint32LE(p unsafe.Pointer, i uintptr, x uint32) {
+	p = PointerAdd(p, i)
+	if supportsUnaligned {
+		if isBig {
+			x = bits.ReverseBytes32(x)
+		}
+		*(*uint32)(p) = x
+		return
+	}
+	q := (*[4]byte)(p)
+	q[0], q[1], q[2], q[3] = byte(x), byte(x>>8), byte(x>>16), byte(x>>24)
+}
+
+func StoreUint32BE(p unsafe.Pointer, i uintptr, x uint32) {
+	p = PointerAdd(p, i)
+	if supportsUnaligned {
+		if !isBig {
+			x = bits.ReverseBytes32(x)
+		}
+		*(*uint32)(p) = x
+		return
+	}
+	q := (*[4]byte)(p)
+	q[0], q[1], q[2], q[3] = byte(x>>24), byte(x>>16), byte(x>>8), byte(x)
+}
+
+func LoadUint64LE(p unsafe.Pointer, i uintptr) uint64 {
+	p = PointerAdd(p, i)
+	if supportsUnaligned {
+		x := *(*uint64)(p)
+		if isBig {
+			x = bits.ReverseBytes64(x)
+		}
+		return x
+	}
+	q := (*[8]byte)(p)
+	return uint64(q[0]) | uint64(q[1])<<8 | uint64(q[2])<<16 | uint64(q[3])<<24 |
+		uint64(q[4])<<32 | uint64(q[5])<<40 | uint64(q[6])<<48 | uint64(q[7])<<56
+}
+
+func LoadUint64BE(p unsafe.Pointer, i uintptr) uint64 {
+	p = PointerAdd(p, i)
+	if supportsUnaligned {
+		x := *(*uint64)(p)
+		if !isBig {
+			x = bits.ReverseBytes64(x)
+		}
+		return x
+	}
+	q := (*[8]byte)(p)
+	return uint64(q[7]) | uint64(q[6])<<8 | uint64(q[5])<<16 | uint64(q[4])<<24 |
+		uint64(q[3])<<32 | uint64(q[2])<<40 | uint64(q[1])<<48 | uint64(q[0])<<56
+}
+
+func StoreUint64LE(p unsafe.Pointer, i uintptr, x uint64) {
+	p = PointerAdd(p, i)
+	if supportsUnaligned {
+		if isBig {
+			x = bits.ReverseBytes64(x)
+		}
+		*(*uint64)(p) = x
+		return
+	}
+	q := (*[8]byte)(p)
+	q[0], q[1], q[2], q[3] = byte(x), byte(x>>8), byte(x>>16), byte(x>>24)
+	q[4], q[5], q[6], q[7] = byte(x>>32), byte(x>>40), byte(x>>48), byte(x>>56)
+}
+
+func StoreUint64BE(p unsafe.Pointer, i uintptr, x uint64) {
+	p = PointerAdd(p, i)
+	if supportsUnaligned {
+		if !isBig {
+			x = bits.ReverseBytes64(x)
+		}
+		*(*uint64)(p) = x
+		return
+	}
+	q := (*[8]byte)(p)
+	q[0], q[1], q[2], q[3] = byte(x>>56), byte(x>>48), byte(x>>40), byte(x>>32)
+	q[4], q[5], q[6], q[7] = byte(x>>24), byte(x>>16), byte(x>>8), byte(x)
+}
+
 func PointerAdd(p unsafe.Pointer, i uintptr) unsafe.Pointer { return unsafe.Pointer(uintptr(p) + i) }
 
 func PointerPoison(p unsafe.Pointer, n uintptr) {
@@ -95,8 +283,31 @@ func PointerPoison(p unsafe.Pointer, n uintptr) {
 	}
 }
 
+// MemorySize is a number of bytes printed and parsed with binary unit suffix (e.g. 64K, 2MiB, 1.5G).
 type MemorySize uint64
 
+// ParseMemorySize parses sizes as printed by String; K, M, G, T, P and E are powers of 1024
+// and may be followed by B or iB.
+func ParseMemorySize(s string) (m MemorySize, err error) {
+	v, neg, err := parseUnits(s, true)
+	if err == nil && neg && v != 0 {
+		err = ErrUnitsSyntax
+	}
+	if err == nil {
+		m = MemorySize(v)
+	}
+	return
+}
+
+// Set implements flag.Value.
+func (s *MemorySize) Set(v string) (err error) {
+	var m MemorySize
+	if m, err = ParseMemorySize(v); err == nil {
+		*s = m
+	}
+	return
+}
+
 func (s MemorySize) String() (v string) {
 	u, c := uint64(1), rune(0)
 	switch {
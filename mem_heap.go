@@ -28,6 +28,12 @@ type MemHeap struct {
 
 	// Virtual address lines returned via mmap of anonymous memory.
 	data []byte
+
+	// Log2 size of pages backing data; zero if data was given by caller.
+	log2PageBytes uint
+
+	// Allocation guards when Debug is enabled.
+	guard memHeapGuard
 }
 
 func RawMmap(addr, length, prot, flags, fd, offset uintptr) (a uintptr, b []byte, err error) {
@@ -52,6 +58,7 @@ func (h *MemHeap) init(b []byte, n uint) {
 			err = fmt.Errorf("mmap: %s", err)
 			panic(err)
 		}
+		h.log2PageBytes = MinLog2(Word(syscall.Getpagesize()))
 	}
 	n = uint(len(b)) &^ (cpu.CacheLineBytes - 1)
 	h.data = b[:n]
@@ -65,6 +72,9 @@ func (h *MemHeap) Init(n uint) (err error) {
 
 func (h *MemHeap) InitData(b []byte) { h.init(b, 0) }
 
+// SetPolicy selects allocation policy; must be called before first allocation.
+func (h *MemHeap) SetPolicy(p HeapPolicy) { h.heap.SetPolicy(p) }
+
 func (h *MemHeap) GetAligned(n, log2Align uint) (b []byte, id Index, offset, cap uint) {
 	// Allocate memory in case caller has not called Init to select a size.
 	if err := h.Init(64 << 20); err != nil {
@@ -80,22 +90,126 @@ func (h *MemHeap) GetAligned(n, log2Align uint) (b []byte, id Index, offset, cap
 	log2Align -= cpu.Log2CacheLineBytes
 
 	cap = uint(Word(n).RoundCacheLine())
-	id, i := h.heap.GetAligned(cap>>cpu.Log2CacheLineBytes, log2Align)
+	l, oldLen := cap, h.heap.len
+	if Debug {
+		l += memHeapGuardBytes
+	}
+	id, i := h.heap.GetAligned(l>>cpu.Log2CacheLineBytes, log2Align)
 	offset = uint(i) << cpu.Log2CacheLineBytes
 	b = h.data[offset : offset+cap]
+	if Debug {
+		h.guardGet(id, offset, cap, oldLen)
+	}
 	return
 }
 
 func (h *MemHeap) Get(n uint) (b []byte, id Index, offset, cap uint) { return h.GetAligned(n, 0) }
 
+// Resize grows or shrinks allocation in place when neighboring memory is free.
+// Otherwise data is moved to new allocation; returned id and offset may differ from original.
+func (h *MemHeap) Resize(id Index, n uint) (b []byte, newId Index, offset, cap uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var g *memHeapAlloc
+	if Debug {
+		var err error
+		if g, err = h.guardCheck(id); err != nil {
+			panic(err)
+		}
+	}
+
+	o, l := h.heap.GetID(id)
+	cap = uint(Word(n).RoundCacheLine())
+	size := cap
+	if Debug {
+		// Data only: guard is rewritten after resize.
+		size += memHeapGuardBytes
+		l -= memHeapGuardBytes >> cpu.Log2CacheLineBytes
+	}
+	newId, i := h.heap.Resize(id, size>>cpu.Log2CacheLineBytes)
+	offset = uint(i) << cpu.Log2CacheLineBytes
+	if old := uint(o) << cpu.Log2CacheLineBytes; offset != old {
+		m := uint(l) << cpu.Log2CacheLineBytes
+		if m > cap {
+			m = cap
+		}
+		copy(h.data[offset:offset+m], h.data[old:old+m])
+	}
+	if Debug {
+		h.guardResize(id, newId, g, offset, cap)
+	}
+	b = h.data[offset : offset+cap]
+	return
+}
+
 func (h *MemHeap) Put(id Index) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	if Debug {
+		h.guardPut(id)
+	}
 	h.heap.Put(id)
 }
 
+// Fragmentation statistics in units of cache lines.
+func (h *MemHeap) Fragmentation() HeapFragmentation {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.heap.GetFragmentation()
+}
+
+// Compact moves allocations towards start of heap.  Data is moved by Compact; moved is called
+// (when non-nil) with old and new byte offsets so caller can update references to moved allocations.
+// Returns number of bytes released from end of heap.  Whole pages of released memory are given
+// back to the OS with madvise (unless heap data was given by caller with InitData).
+func (h *MemHeap) Compact(moved func(id Index, oldOffset, newOffset uint)) (released uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	l := cpu.Log2CacheLineBytes
+	released = h.heap.Compact(func(id Index, o, n, size uint) {
+		o, n, size = o<<l, n<<l, size<<l
+		copy(h.data[n:n+size], h.data[o:o+size])
+		if Debug {
+			if a := h.guard.allocs[id]; a != nil {
+				a.offset = n
+			}
+		}
+		if moved != nil {
+			moved(id, o, n)
+		}
+	}) << l
+	if released > 0 {
+		h.releasePages(uint(h.heap.len)<<l, released)
+	}
+	if Debug && released > 0 {
+		// Free memory is now all at end of heap.
+		h.fill(uint(h.heap.len)<<l, released, memHeapPoison)
+		h.guard.freed = make(map[uint]*memHeapAlloc)
+	}
+	return
+}
+
+// Return pages wholly within n bytes at offset to OS.  Pages read as zero when next used.
+// Huge pages backed by hugetlbfs or memfd files stay allocated.
+func (h *MemHeap) releasePages(offset, n uint) {
+	if h.log2PageBytes == 0 {
+		return
+	}
+	m := uint(1)<<h.log2PageBytes - 1
+	lo, hi := (offset+m)&^m, (offset+n)&^m
+	if lo < hi {
+		syscall.Madvise(h.data[lo:hi], syscall.MADV_DONTNEED)
+	}
+}
+
 func (h *MemHeap) GetId(id Index) (b []byte) {
 	offset, len := h.heap.GetID(id)
+	offset <<= cpu.Log2CacheLineBytes
+	len <<= cpu.Log2CacheLineBytes
+	if Debug {
+		len -= memHeapGuardBytes
+	}
 	return h.data[offset : offset+len]
 }
 
@@ -112,8 +226,12 @@ func (h *MemHeap) String() string {
 		return "empty"
 	}
 	u := h.heap.GetUsage()
-	return fmt.Sprintf("used %s, free %s, capacity %s",
+	s := fmt.Sprintf("used %s, free %s, capacity %s",
 		MemorySize(u.Used<<cpu.Log2CacheLineBytes),
 		MemorySize(u.Free<<cpu.Log2CacheLineBytes),
 		MemorySize(max<<cpu.Log2CacheLineBytes))
+	if l := h.log2PageBytes; l > MinLog2(Word(syscall.Getpagesize())) {
+		s += fmt.Sprintf(", %s pages", MemorySize(uint64(1)<<l))
+	}
+	return s
 }
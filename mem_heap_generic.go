@@ -0,0 +1,13 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !linux !amd64,!arm
+
+package elib
+
+// Huge page memfd and NUMA binding are not supported: MemHeap falls back to anonymous memory.
+const (
+	sysMemfdCreate = 0
+	sysMbind       = 0
+)
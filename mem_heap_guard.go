@@ -0,0 +1,200 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+import (
+	"github.com/platinasystems/elib/cpu"
+
+	"fmt"
+	"runtime"
+	"strings"
+	"unsafe"
+)
+
+// When Debug is enabled MemHeap allocations are followed by a guard cache line filled with a canary
+// which is verified when allocation is freed.  Freed memory is poisoned and verified to still be poisoned
+// when it is allocated again.  Errors panic with call sites of allocation (and free).
+//
+// Guards apply only to MemHeap since it owns the memory it allocates.  Heap only allocates
+// index ranges and heap.tmpl (or TypedHeap) data has element type chosen by caller, so
+// their allocations are not guarded.
+
+const (
+	memHeapGuardBytes = cpu.CacheLineBytes
+	memHeapCanary     = uint64(0xfeedfacecafef00d)
+	memHeapPoison     = uint64(0xdeadbeefdeadbeef)
+)
+
+type memHeapAlloc struct {
+	// Byte offset and size of allocation not including guard.
+	offset, cap uint
+
+	// Call sites of allocation and free.
+	allocSite, freeSite string
+}
+
+type memHeapGuard struct {
+	// Live allocations indexed by heap id.
+	allocs map[Index]*memHeapAlloc
+
+	// Freed allocations indexed by byte offset.
+	freed map[uint]*memHeapAlloc
+}
+
+// Call site outside of MemHeap as "function file:line" for up to 3 callers.
+func memHeapCallSite() string {
+	var pcs [16]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	var s []string
+	for len(s) < 3 {
+		f, more := frames.Next()
+		if !strings.HasSuffix(f.File, "/mem_heap.go") && !strings.HasSuffix(f.File, "/mem_heap_guard.go") {
+			s = append(s, fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line))
+		}
+		if !more {
+			break
+		}
+	}
+	if len(s) == 0 {
+		return "unknown"
+	}
+	return strings.Join(s, " <- ")
+}
+
+func (h *MemHeap) word(o uint) *uint64 { return (*uint64)(unsafe.Pointer(&h.data[o])) }
+
+func (h *MemHeap) fill(o, n uint, v uint64) {
+	for i := uint(0); i < n; i += 8 {
+		*h.word(o + i) = v
+	}
+}
+
+// Returns offset of first word not equal to v.
+func (h *MemHeap) check(o, n uint, v uint64) (bad uint, ok bool) {
+	for i := uint(0); i < n; i += 8 {
+		if *h.word(o + i) != v {
+			return o + i, false
+		}
+	}
+	return 0, true
+}
+
+func (a *memHeapAlloc) String() (s string) {
+	s = fmt.Sprintf("%d byte block at offset 0x%x allocated at %s", a.cap, a.offset, a.allocSite)
+	if a.freeSite != "" {
+		s += ", freed at " + a.freeSite
+	}
+	return
+}
+
+// Freed allocation containing given offset.
+func (g *memHeapGuard) freedAt(o uint) string {
+	for _, a := range g.freed {
+		if o >= a.offset && o < a.offset+a.cap+memHeapGuardBytes {
+			return a.String()
+		}
+	}
+	return "unknown block"
+}
+
+func (g *memHeapGuard) init() {
+	if g.allocs == nil {
+		g.allocs = make(map[Index]*memHeapAlloc)
+		g.freed = make(map[uint]*memHeapAlloc)
+	}
+}
+
+// Called after heap has allocated cap plus guard bytes at offset.  Heap length was oldLen before allocation.
+func (h *MemHeap) guardGet(id Index, offset, cap uint, oldLen Index) {
+	g := &h.guard
+	g.init()
+
+	// Poison memory newly added to heap.
+	l := cpu.Log2CacheLineBytes
+	if newLen := h.heap.len; newLen > oldLen {
+		h.fill(uint(oldLen)<<l, uint(newLen-oldLen)<<l, memHeapPoison)
+	}
+
+	n := cap + memHeapGuardBytes
+	if bad, ok := h.check(offset, n, memHeapPoison); !ok {
+		panic(fmt.Errorf("mem heap: use after free: offset 0x%x written after free of %s", bad, g.freedAt(bad)))
+	}
+	for o, a := range g.freed {
+		if a.offset >= offset && a.offset+a.cap+memHeapGuardBytes <= offset+n {
+			delete(g.freed, o)
+		}
+	}
+	h.fill(offset+cap, memHeapGuardBytes, memHeapCanary)
+	g.allocs[id] = &memHeapAlloc{offset: offset, cap: cap, allocSite: memHeapCallSite()}
+}
+
+func (h *MemHeap) guardCheck(id Index) (a *memHeapAlloc, err error) {
+	g := &h.guard
+	g.init()
+	var ok bool
+	if a, ok = g.allocs[id]; !ok {
+		o, _ := h.heap.GetID(id)
+		err = fmt.Errorf("mem heap: id %d not allocated (%s)", id, g.freedAt(uint(o)<<cpu.Log2CacheLineBytes))
+		return
+	}
+	if bad, ok := h.check(a.offset+a.cap, memHeapGuardBytes, memHeapCanary); !ok {
+		err = fmt.Errorf("mem heap: overrun at offset 0x%x of %s", bad, a)
+	}
+	return
+}
+
+func (h *MemHeap) guardPut(id Index) {
+	a, err := h.guardCheck(id)
+	if err != nil {
+		panic(err)
+	}
+	h.fill(a.offset, a.cap+memHeapGuardBytes, memHeapPoison)
+	a.freeSite = memHeapCallSite()
+	delete(h.guard.allocs, id)
+	h.guard.freed[a.offset] = a
+}
+
+// Called after resize of allocation from old to new offset and size; old canary has been checked.
+func (h *MemHeap) guardResize(id, newId Index, old *memHeapAlloc, offset, cap uint) {
+	g := &h.guard
+	o0, e0 := old.offset, old.offset+old.cap+memHeapGuardBytes
+	o1, e1 := offset, offset+cap+memHeapGuardBytes
+	// Poison memory no longer part of allocation.
+	if o0 < o1 {
+		e := e0
+		if e > o1 {
+			e = o1
+		}
+		h.fill(o0, e-o0, memHeapPoison)
+	}
+	if e0 > e1 {
+		o := o0
+		if o < e1 {
+			o = e1
+		}
+		h.fill(o, e0-o, memHeapPoison)
+	}
+	h.fill(offset+cap, memHeapGuardBytes, memHeapCanary)
+	delete(g.allocs, id)
+	old.offset, old.cap = offset, cap
+	g.allocs[newId] = old
+}
+
+// CheckGuards verifies canaries of all allocations returning error for first overrun found.
+// Does nothing unless Debug is enabled.
+func (h *MemHeap) CheckGuards() (err error) {
+	if !Debug {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for id := range h.guard.allocs {
+		if _, err = h.guardCheck(id); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,144 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// MemHeapConfig selects backing memory for MemHeap.
+type MemHeapConfig struct {
+	// Log2 huge page size: 21 for 2MB or 30 for 1GB pages.  Zero means normal pages.
+	Log2HugePageBytes uint
+
+	// Directory where hugetlbfs is mounted (e.g. /dev/hugepages).
+	// If empty memfd_create with MFD_HUGETLB is used.
+	HugetlbfsDir string
+
+	// Bind memory to NumaNode with mbind.
+	BindNumaNode bool
+	NumaNode     uint
+}
+
+const (
+	mfdCloexec    = 0x1
+	mfdHugetlb    = 0x4
+	hugeShift     = 26 // MFD_HUGE_SHIFT and MAP_HUGE_SHIFT
+	mpolBind      = 2
+	mapHugetlb    = 0x40000
+	protReadWrite = syscall.PROT_READ | syscall.PROT_WRITE
+)
+
+func rawMunmap(b []byte) {
+	syscall.RawSyscall(syscall.SYS_MUNMAP, uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)), 0)
+}
+
+func mmapFd(fd int, n uint) (b []byte, err error) {
+	if err = syscall.Ftruncate(fd, int64(n)); err != nil {
+		return
+	}
+	_, b, err = RawMmap(0, uintptr(n), protReadWrite, syscall.MAP_SHARED, uintptr(fd), 0)
+	return
+}
+
+// Map n bytes of huge pages trying hugetlbfs (when configured), memfd and finally anonymous huge pages.
+func (c *MemHeapConfig) mmapHuge(n uint) (b []byte, err error) {
+	l := c.Log2HugePageBytes
+	if c.HugetlbfsDir != "" {
+		var f *os.File
+		if f, err = ioutil.TempFile(c.HugetlbfsDir, "elib-mem-heap-"); err == nil {
+			os.Remove(f.Name())
+			b, err = mmapFd(int(f.Fd()), n)
+			f.Close()
+			if err == nil {
+				return
+			}
+		}
+	}
+	if sysMemfdCreate != 0 {
+		name := []byte("elib-mem-heap\x00")
+		fd, _, e := syscall.Syscall(sysMemfdCreate, uintptr(unsafe.Pointer(&name[0])),
+			mfdCloexec|mfdHugetlb|uintptr(l)<<hugeShift, 0)
+		if e == 0 {
+			b, err = mmapFd(int(fd), n)
+			syscall.Close(int(fd))
+			if err == nil {
+				return
+			}
+		}
+	}
+	_, b, err = RawMmap(0, uintptr(n), protReadWrite,
+		syscall.MAP_PRIVATE|syscall.MAP_ANON|mapHugetlb|uintptr(l)<<hugeShift, 0, 0)
+	return
+}
+
+func mbind(b []byte, node uint) (err error) {
+	if sysMbind == 0 {
+		return fmt.Errorf("mbind: not supported")
+	}
+	mask := make([]uint64, 1+node/64)
+	mask[node/64] |= 1 << (node % 64)
+	_, _, e := syscall.Syscall6(sysMbind, uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)),
+		mpolBind, uintptr(unsafe.Pointer(&mask[0])), uintptr(64*len(mask)+1), 0)
+	if e != 0 {
+		err = fmt.Errorf("mbind node %d: %s", node, e)
+	}
+	return
+}
+
+// Map memory as specified by config falling back to normal pages.
+func (c *MemHeapConfig) mmap(n uint) (b []byte, log2PageBytes uint, err error) {
+	if l := c.Log2HugePageBytes; l != 0 {
+		m := uint(1)<<l - 1
+		if b, err = c.mmapHuge((n + m) &^ m); err == nil {
+			log2PageBytes = l
+		} else {
+			b = nil
+		}
+	}
+	if b == nil {
+		_, b, err = RawMmap(0, uintptr(n), protReadWrite,
+			syscall.MAP_PRIVATE|syscall.MAP_ANON|syscall.MAP_NORESERVE, 0, 0)
+		if err != nil {
+			return
+		}
+		log2PageBytes = MinLog2(Word(syscall.Getpagesize()))
+	}
+	if c.BindNumaNode {
+		if err = mbind(b, c.NumaNode); err != nil {
+			rawMunmap(b)
+			b = nil
+		}
+	}
+	return
+}
+
+// ErrMemHeapInitialized is returned when heap is configured after first Init or allocation.
+var ErrMemHeapInitialized = errors.New("mem heap: already initialized")
+
+// InitConfig initializes heap with n bytes of memory backed as given by config.
+// Falls back to normal pages when huge pages are not available.
+// If memory can not be mapped or bound to NUMA node heap still falls back to
+// normal pages and error is returned.
+func (h *MemHeap) InitConfig(n uint, c *MemHeapConfig) (err error) {
+	err = ErrMemHeapInitialized
+	h.once.Do(func() {
+		var b []byte
+		b, h.log2PageBytes, err = c.mmap(n)
+		if err != nil {
+			b = nil
+		}
+		h.init(b, n)
+	})
+	return
+}
+
+// Log2PageBytes gives log2 size of pages backing heap (e.g. 21 for 2MB huge pages).
+func (h *MemHeap) Log2PageBytes() uint { return h.log2PageBytes }
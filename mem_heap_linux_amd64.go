@@ -0,0 +1,11 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+// Linux system call numbers missing from package syscall.
+const (
+	sysMemfdCreate = 319
+	sysMbind       = 237
+)
@@ -0,0 +1,148 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+func TestMemHeapHugePages(t *testing.T) {
+	var h MemHeap
+	c := MemHeapConfig{Log2HugePageBytes: 21}
+	if err := h.InitConfig(4<<20, &c); err != nil {
+		t.Fatal(err)
+	}
+	// Either huge pages or fallback to normal pages.
+	if l := h.Log2PageBytes(); l != 21 && l != MinLog2(Word(syscall.Getpagesize())) {
+		t.Fatalf("unexpected page size 2^%d", l)
+	}
+	b, id, _, _ := h.Get(1 << 20)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	h.Put(id)
+	if err := h.InitConfig(4<<20, &c); err != ErrMemHeapInitialized {
+		t.Fatalf("second InitConfig: %v", err)
+	}
+}
+
+func TestMemHeapConfigFallback(t *testing.T) {
+	var h MemHeap
+	c := MemHeapConfig{BindNumaNode: true, NumaNode: 1000}
+	if err := h.InitConfig(1<<20, &c); err == nil {
+		t.Skip("bind to numa node 1000 succeeded")
+	}
+	if l := h.Log2PageBytes(); l != MinLog2(Word(syscall.Getpagesize())) {
+		t.Fatalf("unexpected page size 2^%d", l)
+	}
+	b, id, _, _ := h.Get(1 << 10)
+	b[0] = 1
+	h.Put(id)
+}
+
+func expectPanic(t *testing.T, what, want string, f func()) {
+	defer func() {
+		e := recover()
+		if e == nil {
+			t.Fatalf("%s: no panic", what)
+		}
+		if s := fmt.Sprint(e); !strings.Contains(s, want) || !strings.Contains(s, "TestMemHeapGuard") {
+			t.Fatalf("%s: unexpected panic: %s", what, s)
+		}
+	}()
+	f()
+}
+
+func TestMemHeapGuard(t *testing.T) {
+	if !Debug {
+		t.Skip("guards require debug")
+	}
+	var h MemHeap
+	h.Init(1 << 20)
+
+	// Overrun into guard.
+	b, id, _, _ := h.Get(100)
+	b = b[:len(b)+1]
+	b[len(b)-1] = 0
+	if err := h.CheckGuards(); err == nil {
+		t.Fatal("overrun not detected by CheckGuards")
+	}
+	expectPanic(t, "overrun", "overrun", func() { h.Put(id) })
+
+	// Use after free.
+	b, id, _, _ = h.Get(256)
+	h.Put(id)
+	b[10] = 1
+	expectPanic(t, "use after free", "use after free", func() { h.Get(256) })
+
+	// Valid use including resize.
+	var h1 MemHeap
+	h1.Init(1 << 20)
+	b, id, _, _ = h1.Get(64)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	_, id2, _, _ := h1.Get(64)
+	b, id, _, _ = h1.Resize(id, 1000)
+	for i := 0; i < 64; i++ {
+		if b[i] != byte(i) {
+			t.Fatalf("resize lost data at %d", i)
+		}
+	}
+	h1.Put(id2)
+	b, id, _, _ = h1.Resize(id, 64)
+	if err := h1.CheckGuards(); err != nil {
+		t.Fatal(err)
+	}
+	h1.Put(id)
+	b, id, _, _ = h1.Get(2000)
+	h1.Put(id)
+}
+
+func TestMemHeapCompact(t *testing.T) {
+	var h MemHeap
+	h.Init(1 << 20)
+	pageBytes := uint(syscall.Getpagesize())
+	log2Page := MinLog2(Word(pageBytes))
+	_, free, _, _ := h.Get(256 << 10)
+	b, id, _, _ := h.GetAligned(pageBytes, log2Page)
+	for i := range b {
+		b[i] = 0xaa
+	}
+	tail, tailId, _, _ := h.Get(256 << 10)
+	for i := range tail {
+		tail[i] = 0x55
+	}
+	h.Put(free)
+	h.Put(tailId)
+	moved := false
+	released := h.Compact(func(mid Index, oldOffset, newOffset uint) {
+		if mid == id {
+			moved = true
+			if newOffset%pageBytes != 0 {
+				t.Errorf("page aligned allocation moved to 0x%x", newOffset)
+			}
+		}
+	})
+	if !moved || released < 256<<10 {
+		t.Fatalf("moved %v released %d", moved, released)
+	}
+	b = h.GetId(id)
+	if uintptr(unsafe.Pointer(&b[0]))%uintptr(pageBytes) != 0 || b[0] != 0xaa || b[pageBytes-1] != 0xaa {
+		t.Fatalf("data not moved with alignment")
+	}
+	if !Debug {
+		// Released pages are given back to OS and read as zero.
+		o := h.Offset(b) + pageBytes
+		if x := h.data[o+2*pageBytes]; x != 0 {
+			t.Errorf("released page not zero: 0x%x", x)
+		}
+	}
+	h.Put(id)
+}
@@ -0,0 +1,78 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+	"unsafe"
+)
+
+func TestLoadStoreEndian(t *testing.T) {
+	var b [32]byte
+	p := unsafe.Pointer(&b[0])
+	for iter := 0; iter < 1000; iter++ {
+		rand.Read(b[:])
+		x := rand.Uint64()
+		i := uintptr(rand.Intn(len(b) - 8))
+		s := b[i:]
+
+		if got, want := LoadUint16LE(p, i), binary.LittleEndian.Uint16(s); got != want {
+			t.Fatalf("load 16 le %d: %x != %x", i, got, want)
+		}
+		if got, want := LoadUint16BE(p, i), binary.BigEndian.Uint16(s); got != want {
+			t.Fatalf("load 16 be %d: %x != %x", i, got, want)
+		}
+		if got, want := LoadUint32LE(p, i), binary.LittleEndian.Uint32(s); got != want {
+			t.Fatalf("load 32 le %d: %x != %x", i, got, want)
+		}
+		if got, want := LoadUint32BE(p, i), binary.BigEndian.Uint32(s); got != want {
+			t.Fatalf("load 32 be %d: %x != %x", i, got, want)
+		}
+		if got, want := LoadUint64LE(p, i), binary.LittleEndian.Uint64(s); got != want {
+			t.Fatalf("load 64 le %d: %x != %x", i, got, want)
+		}
+		if got, want := LoadUint64BE(p, i), binary.BigEndian.Uint64(s); got != want {
+			t.Fatalf("load 64 be %d: %x != %x", i, got, want)
+		}
+
+		StoreUint16LE(p, i, uint16(x))
+		if got := binary.LittleEndian.Uint16(s); got != uint16(x) {
+			t.Fatalf("store 16 le %d: %x != %x", i, got, uint16(x))
+		}
+		StoreUint16BE(p, i, uint16(x))
+		if got := binary.BigEndian.Uint16(s); got != uint16(x) {
+			t.Fatalf("store 16 be %d: %x != %x", i, got, uint16(x))
+		}
+		StoreUint32LE(p, i, uint32(x))
+		if got := binary.LittleEndian.Uint32(s); got != uint32(x) {
+			t.Fatalf("store 32 le %d: %x != %x", i, got, uint32(x))
+		}
+		StoreUint32BE(p, i, uint32(x))
+		if got := binary.BigEndian.Uint32(s); got != uint32(x) {
+			t.Fatalf("store 32 be %d: %x != %x", i, got, uint32(x))
+		}
+		StoreUint64LE(p, i, x)
+		if got := binary.LittleEndian.Uint64(s); got != x {
+			t.Fatalf("store 64 le %d: %x != %x", i, got, x)
+		}
+		StoreUint64BE(p, i, x)
+		if got := binary.BigEndian.Uint64(s); got != x {
+			t.Fatalf("store 64 be %d: %x != %x", i, got, x)
+		}
+
+		// Host order stores round trip with unaligned loads.
+		StoreUnalignedUint16(p, i, uint16(x))
+		StoreUnalignedUint32(p, i+2, uint32(x))
+		if UnalignedUint16(p, i) != uint16(x) || UnalignedUint32(p, i+2) != uint32(x) {
+			t.Fatalf("host order 16/32 %d", i)
+		}
+		StoreUnalignedUint64(p, i, x)
+		if UnalignedUint64(p, i) != x {
+			t.Fatalf("host order 64 %d", i)
+		}
+	}
+}
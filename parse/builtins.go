@@ -5,7 +5,10 @@
 package parse
 
 import (
+	"github.com/platinasystems/elib"
+
 	"regexp"
+	"strconv"
 	"unicode"
 )
 
@@ -88,6 +91,96 @@ func (m StringMap) ParseWithArgs(in *Input, args *Args) {
 	return
 }
 
+// Name token: letters, digits and any of -_. (e.g. foo-bar, foo_bar, 0x12).
+func (in *Input) nameToken() string {
+	return in.TokenF(func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.')
+	})
+}
+
+// Value for name in map or, failing that, number (e.g. 3 or 0x3).
+func (m StringMap) parseName(in *Input) uint {
+	text := in.nameToken()
+	if v, ok := m[text]; ok {
+		return v
+	}
+	if v, err := strconv.ParseUint(text, 0, 0); err == nil && text != "" {
+		return uint(v)
+	}
+	panic(ErrInput)
+}
+
+// StringerMap parses names from table used by elib.Stringer.
+// Values without names are given as numbers as printed by elib.Stringer (e.g. 5 or 0x5).
+type StringerMap StringMap
+
+func NewStringerMap(a []string) StringerMap { return StringerMap(NewStringMap(a)) }
+
+func (m StringerMap) ParseWithArgs(in *Input, args *Args) {
+	args.SetNextInt(uint64(StringMap(m).parseName(in)))
+}
+
+// FlagStringerMap parses flags from table of bit names used by elib.FlagStringer.
+// Flags are separated by | or , (e.g. "next-valid|cloned" or "active, polling").
+// Bits without names are given as bit numbers as printed by elib.FlagStringer.
+type FlagStringerMap StringMap
+
+func NewFlagStringerMap(a []string) FlagStringerMap { return FlagStringerMap(NewStringMap(a)) }
+
+func (m FlagStringerMap) ParseWithArgs(in *Input, args *Args) {
+	var x uint64
+	for {
+		i := StringMap(m).parseName(in)
+		if i >= 64 {
+			panic(IntegerOverflow)
+		}
+		x |= 1 << i
+		if in.End() || in.AtOneof("|,") == 2 {
+			break
+		}
+	}
+	args.SetNextInt(x)
+}
+
+// Bitmap parses elib.Bitmap as range list (e.g. 1-4,7,32-63) or hex mask (e.g. 0xff00).
+// Input.Parse also accepts *elib.Bitmap directly.
+type Bitmap elib.Bitmap
+
+func (b *Bitmap) Parse(in *Input) {
+	t := in.Token()
+	if t == "" {
+		panic(ErrInput)
+	}
+	x, err := elib.ParseBitmap(t)
+	if err != nil {
+		panic(err)
+	}
+	*b = Bitmap(x)
+}
+
+// Parse token with flag.Value Set method.
+func (in *Input) parseValue(v interface{ Set(string) error }) {
+	t := in.Token()
+	if t == "" {
+		panic(ErrInput)
+	}
+	if err := v.Set(t); err != nil {
+		panic(err)
+	}
+}
+
+// MemorySize parses elib.MemorySize with binary unit suffix (e.g. 64k, 2MiB, 1.5G).
+// Input.Parse also accepts *elib.MemorySize directly.
+type MemorySize elib.MemorySize
+
+func (x *MemorySize) Parse(in *Input) { in.parseValue((*elib.MemorySize)(x)) }
+
+// Count parses elib.Count with SI or binary unit suffix (e.g. 10e6, 1.5M, 64Ki).
+// Input.Parse also accepts *elib.Count directly.
+type Count elib.Count
+
+func (x *Count) Parse(in *Input) { in.parseValue((*elib.Count)(x)) }
+
 type Regexp struct{ *regexp.Regexp }
 
 func (r *Regexp) Valid() bool { return r.Regexp != nil }
@@ -0,0 +1,111 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+import (
+	"github.com/platinasystems/elib"
+
+	"testing"
+)
+
+func TestStringerMap(t *testing.T) {
+	names := []string{"unknown", "known-allocated", "", "known_free"}
+	m := NewStringerMap(names)
+	for i, s := range []string{"unknown", "known-allocated", "0x2", "known_free", "7"} {
+		var in Input
+		in.Add(s)
+		var x uint8
+		if !in.Parse("%v", m, &x) || !in.End() {
+			t.Fatalf("%s: %v", s, in.Error())
+		}
+		if want := []uint8{0, 1, 2, 3, 7}[i]; x != want {
+			t.Errorf("%s: %d != %d", s, x, want)
+		}
+		if elib.Stringer(names, int(x)) != s && i != 2 {
+			t.Errorf("%s: stringer %s", s, elib.Stringer(names, int(x)))
+		}
+	}
+	var in Input
+	in.Add("bogus")
+	var x uint
+	if in.Parse("%v", m, &x) {
+		t.Errorf("bogus name parsed")
+	}
+}
+
+func TestFlagStringerMap(t *testing.T) {
+	names := []string{"active", "suspended", "resumed", "polling"}
+	m := NewFlagStringerMap(names)
+	for _, c := range []struct {
+		s string
+		x uint32
+	}{
+		{"active", 1},
+		{"active|polling", 9},
+		{"active, polling", 9},
+		{"suspended,resumed | 5", 0x26},
+		{elib.FlagStringer(names, 0xb), 0xb},
+	} {
+		var in Input
+		in.Add(c.s)
+		var x uint32
+		if !in.Parse("%v", m, &x) || !in.End() {
+			t.Fatalf("%s: %v", c.s, in.Error())
+		}
+		if x != c.x {
+			t.Errorf("%s: 0x%x != 0x%x", c.s, x, c.x)
+		}
+	}
+	// Flags followed by more input.
+	var (
+		in Input
+		x  uint32
+		s  string
+	)
+	in.Add("active,polling", "next")
+	if !in.Parse("%v %s", m, &x, &s) || x != 9 || s != "next" {
+		t.Errorf("flags then string: 0x%x %s %v", x, s, in.Error())
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	var (
+		in  Input
+		m   elib.MemorySize
+		c   elib.Count
+		bad elib.Count
+	)
+	in.Add("64KiB 1.5M")
+	if !in.Parse("%v %v", &m, &c) || m != 64<<10 || c != 1500000 {
+		t.Errorf("parse: %d %d %v", m, c, in.Error())
+	}
+	var (
+		m1 MemorySize
+		c1 Count
+	)
+	in.Add("2k 1.5k")
+	if !in.Parse("%v %v", &m1, &c1) || m1 != 2<<10 || c1 != 1500 {
+		t.Errorf("parse: %d %d %v", m1, c1, in.Error())
+	}
+	in.Add("1q")
+	if in.Parse("%v", &bad) {
+		t.Errorf("bad units parsed: %d", bad)
+	}
+}
+
+func TestParseBitmap(t *testing.T) {
+	var (
+		in   Input
+		a, b elib.Bitmap
+	)
+	in.Add("1-4,7 0xf0")
+	if !in.Parse("%v %v", &a, (*Bitmap)(&b)) || a.RangeString() != "1-4,7" || b.RangeString() != "4-7" {
+		t.Errorf("parse: %s %s %v", a.RangeString(), b.RangeString(), in.Error())
+	}
+	in.Add("0-18446744073709551615")
+	if in.Parse("%v", &a) {
+		t.Errorf("huge range parsed")
+	}
+}
@@ -13,11 +13,14 @@ import (
 
 type saveVec []save
 
+// Growth policy and statistics for saveVec.
+var saveVecGrowth = elib.NewGrowth("parse.saveVec", (*save)(nil))
+
 func (p *saveVec) Resize(n uint) {
 	c := elib.Index(cap(*p))
 	l := elib.Index(len(*p)) + elib.Index(n)
 	if l > c {
-		c = elib.NextResizeCap(l)
+		c = saveVecGrowth.NextCap(elib.Index(len(*p)), c, l)
 		q := make([]save, l, c)
 		copy(q, *p)
 		*p = q
@@ -42,7 +45,7 @@ func (p *saveVec) validate(new_len uint, zero *save) *save {
 func (p *saveVec) validateSlowPath(zero *save,
 	c, l, lʹ elib.Index) *save {
 	if l > c {
-		cNext := elib.NextResizeCap(l)
+		cNext := saveVecGrowth.NextCap(lʹ, c, l)
 		q := make([]save, cNext, cNext)
 		copy(q, *p)
 		if zero != nil {
@@ -620,9 +620,20 @@ func (in *Input) doParser(verb rune, p Parser, pa ParserWithArgs, args *Args) {
 	}
 }
 
+// Elib types which can not implement Parser (since elib can not import parse) are parsed
+// via equivalent parse types (e.g. *elib.Bitmap via *Bitmap).
 func (in *Input) doPercent(verb rune, args *Args) {
 	arg := args.Get()
 
+	switch v := arg.(type) {
+	case *elib.Bitmap:
+		arg = (*Bitmap)(v)
+	case *elib.MemorySize:
+		arg = (*MemorySize)(v)
+	case *elib.Count:
+		arg = (*Count)(v)
+	}
+
 	if p, ok := arg.(Parser); ok {
 		in.doParser(verb, p, nil, args)
 		return
@@ -6,28 +6,68 @@ package elib
 
 import (
 	"errors"
+	"sync"
+	"sync/atomic"
 )
 
 type Pool struct {
 	// Vector of free indices
 	freeIndices []uint32 // Uint32Vec
-	// Bitmap of free indices
+	// Bitmap of free indices (not used by concurrent pools).
 	freeBitmap Bitmap
 	// Non-zero to limit size of pool.
 	maxLen uint
+	// Generation of each index; incremented when index is freed.
+	// Allocated on demand when handles are used.
+	generations []uint32
+	// Non-nil for concurrent pools.
+	shared *poolShared
 }
 
 // ErrTooLarge is passed to panic if pool overflows maxLen
 var ErrPoolTooLarge = errors.New("pool: too large")
 
+// ErrPoolMaxLen is passed to panic if concurrent pool is given zero maximum length.
+var ErrPoolMaxLen = errors.New("pool: concurrent pool requires non-zero max length")
+
+func (p *Pool) isFree(i uint) bool {
+	if s := p.shared; s != nil {
+		return s.free.Get(i)
+	}
+	return p.freeBitmap.Get(i)
+}
+
+func (p *Pool) setFree(i uint, v bool) {
+	switch s := p.shared; {
+	case s == nil && v:
+		p.freeBitmap = p.freeBitmap.Orx(i)
+	case s == nil:
+		p.freeBitmap = p.freeBitmap.AndNotx(i)
+	case v:
+		s.free.Alloc(i + 1)
+		s.free.Set(i, true)
+	case i < uint(len(s.free))*bitmapBits:
+		s.free.Unset(i)
+	}
+}
+
 // Get first free pool index if available.
 func (p *Pool) GetIndex(max uint) (i uint) {
+	if s := p.shared; s != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.getIndex(p)
+	}
+	return p.getIndex(max)
+}
+
+func (p *Pool) getIndex(max uint) (i uint) {
 	i = max
 	l := uint(len(p.freeIndices))
 	if l != 0 {
 		i = uint(p.freeIndices[l-1])
 		p.freeIndices = p.freeIndices[:l-1]
-		p.freeBitmap = p.freeBitmap.AndNotx(i)
+		p.setFree(i, false)
 	}
 	if p.maxLen != 0 && i >= p.maxLen {
 		panic(ErrPoolTooLarge)
@@ -37,14 +77,189 @@ func (p *Pool) GetIndex(max uint) (i uint) {
 
 // Put (free) given pool index.
 func (p *Pool) PutIndex(i uint) (ok bool) {
-	if ok = !p.freeBitmap.Get(i); ok {
+	if s := p.shared; s != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	return p.putIndex(i)
+}
+
+func (p *Pool) putIndex(i uint) (ok bool) {
+	if ok = !p.isFree(i); ok {
 		p.freeIndices = append(p.freeIndices, uint32(i))
-		p.freeBitmap = p.freeBitmap.Orx(i)
+		p.setFree(i, true)
+		if i < uint(len(p.generations)) {
+			p.generations[i]++
+		}
+	}
+	return
+}
+
+func (p *Pool) IsFree(i uint) (ok bool) {
+	if s := p.shared; s != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return i >= s.Len() || s.free.Get(i)
+	}
+	return p.freeBitmap.Get(i)
+}
+
+func (p *Pool) FreeLen() (n uint) {
+	if s := p.shared; s != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return uint(len(p.freeIndices)) + p.maxLen - s.Len()
+	}
+	return uint(len(p.freeIndices))
+}
+
+func (p *Pool) MaxLen() uint     { return p.maxLen }
+func (p *Pool) SetMaxLen(x uint) { p.maxLen = x }
+
+// PoolHandle combines pool index with generation of index so that handles to
+// freed (and possibly reused) indices can be detected.
+type PoolHandle uint64
+
+func MakePoolHandle(i uint, generation uint32) PoolHandle {
+	return PoolHandle(generation)<<32 | PoolHandle(uint32(i))
+}
+
+func (h PoolHandle) Index() uint        { return uint(uint32(h)) }
+func (h PoolHandle) Generation() uint32 { return uint32(h >> 32) }
+
+// ErrPoolStaleHandle is returned for handles whose index has been freed.
+var ErrPoolStaleHandle = errors.New("pool: stale handle")
+
+// Handle gives handle for allocated index i.
+func (p *Pool) Handle(i uint) PoolHandle {
+	// Indices without generation have never been part of a handle: start at zero.
+	for uint(len(p.generations)) <= i {
+		p.generations = append(p.generations, 0)
+	}
+	return MakePoolHandle(i, p.generations[i])
+}
+
+// ValidateHandle returns index for handle or ErrPoolStaleHandle if index has been freed since handle was made.
+func (p *Pool) ValidateHandle(h PoolHandle) (i uint, err error) {
+	i = h.Index()
+	if i >= uint(len(p.generations)) || p.generations[i] != h.Generation() || p.IsFree(i) {
+		err = ErrPoolStaleHandle
 	}
 	return
 }
 
-func (p *Pool) IsFree(i uint) (ok bool) { return p.freeBitmap.Get(i) }
-func (p *Pool) FreeLen() uint           { return uint(len(p.freeIndices)) }
-func (p *Pool) MaxLen() uint            { return p.maxLen }
-func (p *Pool) SetMaxLen(x uint)        { p.maxLen = x }
+// Concurrent pools allocate indices via per thread PoolCaches which move free indices
+// to and from the shared pool in batches under lock.
+type poolShared struct {
+	// Number of indices ever allocated: indices >= len are free.
+	len uint64
+
+	mu sync.Mutex
+
+	// Bitmap of free indices private to pool (global Bitmaps pool is not thread safe).
+	free BitmapVec
+
+	// Number of indices moved between caches and pool.
+	batch uint
+}
+
+func (s *poolShared) Len() uint { return uint(atomic.LoadUint64(&s.len)) }
+
+func (s *poolShared) getIndex(p *Pool) (i uint) {
+	l := s.Len()
+	if i = p.getIndex(l); i == l {
+		atomic.StoreUint64(&s.len, uint64(l+1))
+	}
+	return
+}
+
+func (s *poolShared) isFull(p *Pool) bool {
+	return len(p.freeIndices) == 0 && s.Len() >= p.maxLen
+}
+
+// SetConcurrent puts pool in concurrent mode: indices are allocated from any number of threads
+// each with its own PoolCache.  Pool holds at most maxLen indices.  GetIndex and PutIndex lock pool.
+// Indices held by caches count as allocated (e.g. for IsFree and FreeLen) until caches are flushed.
+// Handles are not safe for concurrent use.  Panics with ErrPoolMaxLen if maxLen is zero.
+func (p *Pool) SetConcurrent(batch, maxLen uint) {
+	if maxLen == 0 {
+		panic(ErrPoolMaxLen)
+	}
+	if batch == 0 {
+		batch = 1
+	}
+	p.maxLen = maxLen
+	s := &poolShared{batch: batch}
+	for _, i := range p.freeIndices {
+		s.free.Alloc(uint(i) + 1)
+		s.free.Set(uint(i), true)
+	}
+	p.freeBitmap = p.freeBitmap.Free()
+	p.shared = s
+}
+
+func (p *Pool) IsConcurrent() bool { return p.shared != nil }
+
+// PoolCache caches free indices of a concurrent pool for use by a single thread.
+type PoolCache struct {
+	pool *Pool
+	free []uint32
+}
+
+func (p *Pool) NewCache() *PoolCache {
+	if p.shared == nil {
+		panic("pool: NewCache requires concurrent pool")
+	}
+	return &PoolCache{pool: p}
+}
+
+func (c *PoolCache) refill() {
+	p := c.pool
+	s := p.shared
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for n := s.batch; n > 0 && !s.isFull(p); n-- {
+		c.free = append(c.free, uint32(s.getIndex(p)))
+	}
+	if len(c.free) == 0 {
+		panic(ErrPoolTooLarge)
+	}
+}
+
+// Return n most recently freed indices to pool.
+func (c *PoolCache) flush(n uint) {
+	p := c.pool
+	s := p.shared
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := uint(len(c.free))
+	for i := l - n; i < l; i++ {
+		p.putIndex(uint(c.free[i]))
+	}
+	c.free = c.free[:l-n]
+}
+
+// GetIndex allocates index refilling cache from pool when empty.
+func (c *PoolCache) GetIndex() (i uint) {
+	if len(c.free) == 0 {
+		c.refill()
+	}
+	l := len(c.free) - 1
+	i = uint(c.free[l])
+	c.free = c.free[:l]
+	return
+}
+
+// PutIndex frees index to cache returning a batch to pool when cache is full.
+// Unlike Pool.PutIndex duplicate frees are not detected until indices are flushed to pool.
+func (c *PoolCache) PutIndex(i uint) {
+	c.free = append(c.free, uint32(i))
+	if b := c.pool.shared.batch; uint(len(c.free)) >= 2*b {
+		c.flush(b)
+	}
+}
+
+// Flush returns all cached indices to pool.
+func (c *PoolCache) Flush() { c.flush(uint(len(c.free))) }
+
+func (c *PoolCache) Len() uint { return uint(len(c.free)) }
@@ -0,0 +1,126 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPoolHandle(t *testing.T) {
+	var p StringPool
+	i, h := p.GetHandle()
+	p.Strings[i] = "a"
+	if x, err := p.GetByHandle(h); err != nil || *x != "a" {
+		t.Fatalf("get: %v %v", x, err)
+	}
+	if err := p.PutHandle(h); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.PutHandle(h); err != ErrPoolStaleHandle {
+		t.Fatalf("double put: %v", err)
+	}
+
+	// Index is reused: old handle must be stale.
+	j, h1 := p.GetHandle()
+	if j != i || h1 == h {
+		t.Fatalf("index %d handle %x reused as %d %x", i, h, j, h1)
+	}
+	if _, err := p.GetByHandle(h); err != ErrPoolStaleHandle {
+		t.Fatalf("stale get: %v", err)
+	}
+	if _, err := p.ValidateHandle(h1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.ValidateHandle(MakePoolHandle(100, 0)); err != ErrPoolStaleHandle {
+		t.Fatalf("out of range: %v", err)
+	}
+
+	// Indices allocated without handles still get valid handles.
+	k := p.GetIndex()
+	if _, err := p.ValidateHandle(p.Handle(k)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrentPool(t *testing.T) { testConcurrentPool(t) }
+
+// Concurrent pools must not share state (e.g. global Bitmaps pool).
+func TestConcurrentPools(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			testConcurrentPool(t)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConcurrentPoolMaxLen(t *testing.T) {
+	defer func() {
+		if e := recover(); e != ErrPoolMaxLen {
+			t.Fatalf("expected ErrPoolMaxLen got %v", e)
+		}
+	}()
+	var p StringPool
+	p.SetConcurrent(16, 0)
+}
+
+func testConcurrentPool(t *testing.T) {
+	const (
+		nThreads = 8
+		maxLen   = 1024
+	)
+	var p StringPool
+	p.SetConcurrent(16, maxLen)
+	// Owner of each index: detects indices allocated by more than one thread.
+	var owners [maxLen]int32
+	var wg sync.WaitGroup
+	errs := make(chan error, nThreads)
+	for thread := int32(1); thread <= nThreads; thread++ {
+		wg.Add(1)
+		go func(thread int32) {
+			defer wg.Done()
+			c := p.NewCache()
+			var mine []uint
+			for iter := 0; iter < 10000; iter++ {
+				if len(mine) > 0 && (len(mine) >= maxLen/nThreads || rand.Intn(2) == 0) {
+					k := rand.Intn(len(mine))
+					i := mine[k]
+					mine[k] = mine[len(mine)-1]
+					mine = mine[:len(mine)-1]
+					atomic.StoreInt32(&owners[i], 0)
+					c.PutIndex(i)
+				} else {
+					i := c.GetIndex()
+					if o := atomic.SwapInt32(&owners[i], thread); o != 0 {
+						errs <- fmt.Errorf("index %d allocated by threads %d and %d", i, o, thread)
+						return
+					}
+					mine = append(mine, i)
+				}
+			}
+			for _, i := range mine {
+				atomic.StoreInt32(&owners[i], 0)
+				c.PutIndex(i)
+			}
+			c.Flush()
+		}(thread)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+		return
+	}
+	if n := p.Elts(); n != 0 {
+		t.Errorf("%d elts still allocated", n)
+	}
+}
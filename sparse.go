@@ -6,6 +6,7 @@ package elib
 
 import (
 	"fmt"
+	"sort"
 )
 
 // Sparse arrays map sparse indices into dense indices.
@@ -44,12 +45,17 @@ func (s *Sparse) eltsBefore(i int) Index {
 }
 
 func (s *Sparse) elts() Index {
+	if len(s.count) == 0 {
+		return 0
+	}
 	return s.eltsBefore(len(s.count) - 1)
 }
 
-func (s *Sparse) Set(sparse Index) (dense Index) {
-	i, m := bitmapIndex(uint(sparse))
+// Len returns number of valid sparse indices.
+func (s *Sparse) Len() uint { return uint(s.elts()) }
 
+// Grow bitmap and counts to include word i.
+func (s *Sparse) grow(i uint) {
 	l := len(s.valid)
 	s.valid.Validate(i)
 	s.count.Validate(i)
@@ -61,20 +67,37 @@ func (s *Sparse) Set(sparse Index) (dense Index) {
 			}
 		}
 	}
+}
+
+// Recompute counts for words after i.
+func (s *Sparse) recount(i uint) {
+	e := s.count[i]
+	for j := i; j < uint(len(s.count)); j++ {
+		s.count[j] = e
+		e += int32(NSetBits(Word(s.valid[j])))
+	}
+}
+
+func (s *Sparse) Set(sparse Index) (dense Index) {
+	i, m := bitmapIndex(uint(sparse))
+	s.grow(i)
 
 	v := s.valid[i]
+	dense = Index(s.count[i]) + Index(NSetBits(Word(v&(m-1))))
 	if v&m != 0 {
 		return
 	}
 
 	s.valid[i] = v | m
-	dense = Index(s.count[i]) + Index(NSetBits(Word(v&(m-1))))
 	s.inc(i, +1)
 	return
 }
 
 func (s *Sparse) Unset(sparse Index) (valid bool) {
 	i, m := bitmapIndex(uint(sparse))
+	if i >= uint(len(s.valid)) {
+		return
+	}
 	v := s.valid[i]
 	valid = v&m != 0
 	if valid {
@@ -84,6 +107,131 @@ func (s *Sparse) Unset(sparse Index) (valid bool) {
 	return
 }
 
+// Set or unset n sparse indices starting with first.  Returns number of indices changed.
+func (s *Sparse) setRange(first Index, n uint, set bool) (nChanged uint) {
+	if n == 0 {
+		return
+	}
+	x, last := uint(first), uint(first)+n-1
+	if set {
+		s.grow(last / bitmapBits)
+	} else if l := uint(len(s.valid)) * bitmapBits; x >= l {
+		return
+	} else if last >= l {
+		last = l - 1
+	}
+	for x <= last {
+		i := x / bitmapBits
+		m := ^Bitmap(0) << (x % bitmapBits)
+		if i == last/bitmapBits {
+			m &= ^Bitmap(0) >> (bitmapBits - 1 - last%bitmapBits)
+		}
+		v := s.valid[i]
+		if set {
+			nChanged += NSetBits(Word(m &^ v))
+			s.valid[i] = v | m
+		} else {
+			nChanged += NSetBits(Word(m & v))
+			s.valid[i] = v &^ m
+		}
+		x = (i + 1) * bitmapBits
+	}
+	if nChanged != 0 {
+		s.recount(uint(first) / bitmapBits)
+	}
+	return
+}
+
+// SetRange sets sparse indices first through first+n-1.  Returns number of indices newly set.
+func (s *Sparse) SetRange(first Index, n uint) uint { return s.setRange(first, n, true) }
+
+// UnsetRange unsets sparse indices first through first+n-1.  Returns number of indices unset.
+func (s *Sparse) UnsetRange(first Index, n uint) uint { return s.setRange(first, n, false) }
+
+// Foreach calls f for each valid index in increasing sparse (and therefore dense) order.
+func (s *Sparse) Foreach(f func(sparse, dense Index)) {
+	dense := Index(0)
+	for i := range s.valid {
+		for v := Word(s.valid[i]); v != 0; {
+			var j int
+			v, j = NextSet(v)
+			f(Index(i*bitmapBits+j), dense)
+			dense++
+		}
+	}
+}
+
+// GetSparse gives sparse index for given dense index.
+func (s *Sparse) GetSparse(dense Index) (sparse Index, valid bool) {
+	if dense >= s.elts() {
+		return
+	}
+	// Last word with count <= dense contains dense index.
+	i := sort.Search(len(s.count), func(i int) bool { return Index(s.count[i]) > dense }) - 1
+	v := Word(s.valid[i])
+	for k := dense - Index(s.count[i]); k > 0; k-- {
+		v ^= FirstSet(v)
+	}
+	_, j := NextSet(v)
+	sparse, valid = Index(i*bitmapBits+j), true
+	return
+}
+
+// LowerBound finds smallest valid sparse index greater than or equal to given sparse index.
+func (s *Sparse) LowerBound(sparse Index) (lb, dense Index, valid bool) {
+	i, m := bitmapIndex(uint(sparse))
+	for ; i < uint(len(s.valid)); i++ {
+		v := s.valid[i]
+		if m != 0 {
+			// Mask out indices below sparse in first word.
+			v &^= m - 1
+			m = 0
+		}
+		if v != 0 {
+			_, j := NextSet(Word(v))
+			lb = Index(i*bitmapBits + uint(j))
+			dense = Index(s.count[i]) + Index(NSetBits(Word(s.valid[i]&(firstSet(v)-1))))
+			valid = true
+			return
+		}
+	}
+	return
+}
+
+// MarshalBinary encodes bitmap of valid sparse indices as little endian bytes with trailing zero bytes removed.
+func (s *Sparse) MarshalBinary() ([]byte, error) {
+	const n = bitmapBits / 8
+	data := make([]byte, n*len(s.valid))
+	for i := range data {
+		data[i] = byte(s.valid[i/n] >> (8 * uint(i%n)))
+	}
+	l := len(data)
+	for l > 0 && data[l-1] == 0 {
+		l--
+	}
+	return data[:l], nil
+}
+
+// UnmarshalBinary replaces sparse array with one decoded from MarshalBinary output.
+func (s *Sparse) UnmarshalBinary(data []byte) error {
+	const n = bitmapBits / 8
+	s.valid = s.valid[:0]
+	s.count = s.count[:0]
+	if len(data) == 0 {
+		return nil
+	}
+	s.grow(uint(len(data)-1) / n)
+	for i := range s.valid {
+		s.valid[i] = 0
+		s.count[i] = 0
+	}
+	for i, d := range data {
+		s.valid[i/n] |= Bitmap(d) << (8 * uint(i%n))
+	}
+	s.recount(0)
+	return nil
+}
+
 func (s *Sparse) String() string {
 	return fmt.Sprintf("%d elts", s.elts())
 }
@@ -0,0 +1,109 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSparse(t *testing.T) {
+	c := testSparse{
+		iterations:         10000,
+		nObjects:           100,
+		log2SparseIndexMax: 12,
+		validateEvery:      1,
+	}
+	err := runSparseTest(&c)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSparseRange(t *testing.T) {
+	const n = 1000
+	var (
+		s   Sparse
+		ref [n]bool
+	)
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 1000; iter++ {
+		first, l := uint(r.Intn(n)), uint(r.Intn(200))
+		if first+l > n {
+			l = n - first
+		}
+		set := r.Intn(2) == 0
+		nChanged := uint(0)
+		for i := first; i < first+l; i++ {
+			if ref[i] != set {
+				nChanged++
+			}
+			ref[i] = set
+		}
+		var got uint
+		if set {
+			got = s.SetRange(Index(first), l)
+		} else {
+			got = s.UnsetRange(Index(first), l)
+		}
+		if got != nChanged {
+			t.Fatalf("iter %d: changed %d != %d", iter, got, nChanged)
+		}
+		if err := s.validate(); err != nil {
+			t.Fatalf("iter %d: %v", iter, err)
+		}
+
+		dense := Index(0)
+		var sparse []Index
+		for i := range ref {
+			d, ok := s.Get(Index(i))
+			if ok != ref[i] || (ok && d != dense) {
+				t.Fatalf("iter %d: get %d: %v %d", iter, i, ok, d)
+			}
+			lb, lbDense, lbOk := s.LowerBound(Index(i))
+			if ok && (!lbOk || lb != Index(i) || lbDense != d) {
+				t.Fatalf("iter %d: lower bound %d: %v %d %d", iter, i, lbOk, lb, lbDense)
+			}
+			if lbOk && (lb < Index(i) || !ref[lb] || lbDense != dense) {
+				t.Fatalf("iter %d: lower bound %d: %d %d", iter, i, lb, lbDense)
+			}
+			if ok {
+				if x, ok := s.GetSparse(d); !ok || x != Index(i) {
+					t.Fatalf("iter %d: get sparse %d: %v %d", iter, d, ok, x)
+				}
+				sparse = append(sparse, Index(i))
+				dense++
+			}
+		}
+		if s.Len() != uint(dense) {
+			t.Fatalf("iter %d: len %d != %d", iter, s.Len(), dense)
+		}
+		if _, ok := s.GetSparse(dense); ok {
+			t.Fatalf("iter %d: get sparse past end", iter)
+		}
+		k := 0
+		s.Foreach(func(x, d Index) {
+			if x != sparse[k] || d != Index(k) {
+				t.Fatalf("iter %d: foreach %d: %d %d", iter, k, x, d)
+			}
+			k++
+		})
+		if k != len(sparse) {
+			t.Fatalf("iter %d: foreach %d != %d", iter, k, len(sparse))
+		}
+
+		var u Sparse
+		b, _ := s.MarshalBinary()
+		u.UnmarshalBinary(b)
+		if err := u.validate(); err != nil || u.Len() != s.Len() {
+			t.Fatalf("iter %d: unmarshal: %v len %d", iter, err, u.Len())
+		}
+		u.Foreach(func(x, d Index) {
+			if e, _ := s.Get(x); e != d {
+				t.Fatalf("iter %d: unmarshal %d: %d != %d", iter, x, d, e)
+			}
+		})
+	}
+}
@@ -8,15 +8,21 @@ package elib
 
 import (
 	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 type row struct {
 	cols []string
+	// Field values used for sorting and JSON output.
+	vals []reflect.Value
 }
 
 type col struct {
@@ -30,6 +36,8 @@ type col struct {
 type table struct {
 	cols []col
 	rows []row
+	// Columns enabled by Select; nil means all columns.
+	colMap map[string]bool
 }
 
 func (c *col) getWidth() int {
@@ -106,7 +114,243 @@ func (t *table) WriteCols(iw io.Writer, colMap map[string]bool) {
 	return
 }
 
-func (t *table) Write(iw io.Writer) { t.WriteCols(iw, nil) }
+func (t *table) Write(iw io.Writer) { t.WriteCols(iw, t.colMap) }
+
+// TableFormat selects output format for tables.
+type TableFormat int
+
+const (
+	// Aligned text.
+	TableText TableFormat = iota
+	// Comma separated values with header row of field names.
+	TableCSV
+	// One JSON object per row keyed by field name.
+	TableJSON
+	// Markdown table.
+	TableMarkdown
+)
+
+var tableFormatNames = [...]string{
+	TableText:     "text",
+	TableCSV:      "csv",
+	TableJSON:     "json",
+	TableMarkdown: "markdown",
+}
+
+func (f TableFormat) String() string { return StringerHex(tableFormatNames[:], int(f)) }
+
+var ErrTableFormat = errors.New("tabulate: unknown format")
+
+// Set sets format from name (e.g. "csv") so that TableFormat can be used as a flag.Value.
+func (f *TableFormat) Set(s string) error {
+	for i, n := range tableFormatNames {
+		if s == n {
+			*f = TableFormat(i)
+			return nil
+		}
+	}
+	return ErrTableFormat
+}
+
+// Column index for name: either field name or display name, ignoring case.
+func (t *table) colIndex(name string) (i int, ok bool) {
+	for i = range t.cols {
+		c := &t.cols[i]
+		if strings.EqualFold(name, c.name) || strings.EqualFold(name, c.displayName()) {
+			ok = true
+			return
+		}
+	}
+	return
+}
+
+// Select enables only columns with given names.
+func (t *table) Select(names ...string) (err error) {
+	m := make(map[string]bool)
+	for i := range t.cols {
+		m[t.cols[i].name] = false
+	}
+	for _, n := range names {
+		i, ok := t.colIndex(n)
+		if !ok {
+			return fmt.Errorf("tabulate: unknown column %s", n)
+		}
+		m[t.cols[i].name] = true
+	}
+	t.colMap = m
+	return
+}
+
+// Kinds compared and written to JSON by value even when type is a Stringer (e.g. MemorySize).
+func isScalarKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Bool:
+		return true
+	}
+	return false
+}
+
+// Numbers, strings and bools compare by value; anything else by formatted string.
+func lessValue(a, b reflect.Value) (less, ok bool) {
+	ok = a.IsValid() && b.IsValid()
+	if !ok {
+		return
+	}
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less = a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		less = a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		less = a.Float() < b.Float()
+	case reflect.String:
+		less = a.String() < b.String()
+	case reflect.Bool:
+		less = !a.Bool() && b.Bool()
+	default:
+		ok = false
+	}
+	return
+}
+
+// Sort sorts rows by named column in increasing (or decreasing if reverse is set) order.
+func (t *table) Sort(name string, reverse bool) (err error) {
+	c, ok := t.colIndex(name)
+	if !ok {
+		return fmt.Errorf("tabulate: unknown column %s", name)
+	}
+	sort.SliceStable(t.rows, func(i, j int) bool {
+		if reverse {
+			i, j = j, i
+		}
+		ri, rj := &t.rows[i], &t.rows[j]
+		if less, ok := lessValue(ri.vals[c], rj.vals[c]); ok {
+			return less
+		}
+		return strings.TrimSpace(ri.cols[c]) < strings.TrimSpace(rj.cols[c])
+	})
+	return
+}
+
+func (t *table) writeCSV(iw io.Writer) error {
+	w := csv.NewWriter(iw)
+	var v []string
+	for c := range t.cols {
+		if t.cols[c].enabled(t.colMap) {
+			v = append(v, t.cols[c].name)
+		}
+	}
+	w.Write(v)
+	for r := range t.rows {
+		v = v[:0]
+		for c := range t.rows[r].cols {
+			if t.cols[c].enabled(t.colMap) {
+				v = append(v, strings.TrimSpace(t.rows[r].cols[c]))
+			}
+		}
+		w.Write(v)
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func (t *table) writeJSON(iw io.Writer) error {
+	w := bufio.NewWriter(iw)
+	for r := range t.rows {
+		w.WriteByte('{')
+		n := 0
+		for c := range t.rows[r].cols {
+			if !t.cols[c].enabled(t.colMap) {
+				continue
+			}
+			if n > 0 {
+				w.WriteByte(',')
+			}
+			n++
+			k, _ := json.Marshal(t.cols[c].name)
+			w.Write(k)
+			w.WriteByte(':')
+			// Numbers and bools are written as such; everything else as formatted string.
+			var (
+				b   []byte
+				err error = ErrTableFormat
+			)
+			switch v := t.rows[r].vals[c]; v.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				b, err = json.Marshal(v.Int())
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+				b, err = json.Marshal(v.Uint())
+			case reflect.Float32, reflect.Float64:
+				b, err = json.Marshal(v.Float())
+			case reflect.Bool:
+				b, err = json.Marshal(v.Bool())
+			case reflect.Invalid, reflect.String:
+			default:
+				b, err = json.Marshal(v.Interface())
+			}
+			if err != nil {
+				b, _ = json.Marshal(strings.TrimSpace(t.rows[r].cols[c]))
+			}
+			w.Write(b)
+		}
+		w.WriteString("}\n")
+	}
+	return w.Flush()
+}
+
+func (t *table) writeMarkdown(iw io.Writer) error {
+	w := bufio.NewWriter(iw)
+	esc := strings.NewReplacer("|", "\\|")
+	w.WriteByte('|')
+	for c := range t.cols {
+		if t.cols[c].enabled(t.colMap) {
+			fmt.Fprintf(w, " %s |", esc.Replace(t.cols[c].displayName()))
+		}
+	}
+	w.WriteString("\n|")
+	for c := range t.cols {
+		if t.cols[c].enabled(t.colMap) {
+			switch t.cols[c].align {
+			case alignLeft:
+				w.WriteString(" :--- |")
+			case alignRight:
+				w.WriteString(" ---: |")
+			default:
+				w.WriteString(" :---: |")
+			}
+		}
+	}
+	w.WriteByte('\n')
+	for r := range t.rows {
+		w.WriteByte('|')
+		for c := range t.rows[r].cols {
+			if t.cols[c].enabled(t.colMap) {
+				fmt.Fprintf(w, " %s |", esc.Replace(strings.TrimSpace(t.rows[r].cols[c])))
+			}
+		}
+		w.WriteByte('\n')
+	}
+	return w.Flush()
+}
+
+// WriteFormat writes table in given format.  Only columns enabled by Select are written.
+func (t *table) WriteFormat(w io.Writer, f TableFormat) (err error) {
+	switch f {
+	case TableText:
+		t.Write(w)
+	case TableCSV:
+		err = t.writeCSV(w)
+	case TableJSON:
+		err = t.writeJSON(w)
+	case TableMarkdown:
+		err = t.writeMarkdown(w)
+	default:
+		err = ErrTableFormat
+	}
+	return
+}
 
 func Tabulate(x interface{}) (tab *table) {
 	v := reflect.ValueOf(x)
@@ -190,6 +434,13 @@ func Tabulate(x interface{}) (tab *table) {
 				v = fmt.Sprintf("%v", fc)
 			}
 			tab.rows[r].cols = append(tab.rows[r].cols, v)
+			// Stringers (e.g. MemorySize) keep underlying value only for scalar kinds so that "2K" sorts
+			// before "1M" and JSON gets numbers; other Stringers use formatted string.
+			var val reflect.Value
+			if fc.CanInterface() && (isScalarKind(ft.Kind()) || !ft.Implements(stringer) && !reflect.PtrTo(ft).Implements(stringer)) {
+				val = fc
+			}
+			tab.rows[r].vals = append(tab.rows[r].vals, val)
 			if l := len(v); l > tab.cols[c].maxLen {
 				tab.cols[c].maxLen = l
 			}
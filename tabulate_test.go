@@ -0,0 +1,81 @@
+// Copyright 2016 Platina Systems, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elib
+
+import (
+	"bytes"
+	"testing"
+)
+
+type testTabulateRow struct {
+	Name  string  `format:"%-10s" align:"left"`
+	Calls uint64  `format:"%8d" align:"right"`
+	Rate  float64 `format:"%.2f"`
+}
+
+func TestTabulateFormats(t *testing.T) {
+	rows := []testTabulateRow{
+		{Name: "b|x", Calls: 10, Rate: 1.5},
+		{Name: "a", Calls: 9, Rate: 0.25},
+		{Name: "c", Calls: 100, Rate: 3},
+	}
+	tab := Tabulate(rows)
+	if err := tab.Sort("calls", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := tab.Select("name", "Calls"); err != nil {
+		t.Fatal(err)
+	}
+	expect := map[TableFormat]string{
+		TableCSV:      "Name,Calls\na,9\nb|x,10\nc,100\n",
+		TableJSON:     `{"Name":"a","Calls":9}` + "\n" + `{"Name":"b|x","Calls":10}` + "\n" + `{"Name":"c","Calls":100}` + "\n",
+		TableMarkdown: "| Name | Calls |\n| :--- | ---: |\n| a | 9 |\n| b\\|x | 10 |\n| c | 100 |\n",
+	}
+	for f, e := range expect {
+		var b bytes.Buffer
+		if err := tab.WriteFormat(&b, f); err != nil {
+			t.Fatalf("%s: %v", f, err)
+		}
+		if b.String() != e {
+			t.Errorf("%s: got\n%s\nexpected\n%s", f, b.String(), e)
+		}
+	}
+
+	if err := tab.Sort("rate", true); err != nil {
+		t.Fatal(err)
+	}
+	tab.Select("rate")
+	var b bytes.Buffer
+	tab.WriteFormat(&b, TableJSON)
+	if e := "{\"Rate\":3}\n{\"Rate\":1.5}\n{\"Rate\":0.25}\n"; b.String() != e {
+		t.Errorf("json: got %q expected %q", b.String(), e)
+	}
+	if err := tab.Sort("none", false); err == nil {
+		t.Errorf("sort by unknown column")
+	}
+	var f TableFormat
+	if err := f.Set("markdown"); err != nil || f != TableMarkdown || f.String() != "markdown" {
+		t.Errorf("set format: %v %s", err, f)
+	}
+}
+
+// Stringers of numeric kind sort and marshal by value rather than by formatted string.
+func TestTabulateStringer(t *testing.T) {
+	rows := []struct{ Size MemorySize }{{2 << 10}, {1 << 20}, {512}}
+	tab := Tabulate(rows)
+	if err := tab.Sort("size", false); err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	tab.WriteFormat(&b, TableJSON)
+	if e := "{\"Size\":512}\n{\"Size\":2048}\n{\"Size\":1048576}\n"; b.String() != e {
+		t.Errorf("json: got %q expected %q", b.String(), e)
+	}
+	b.Reset()
+	tab.WriteFormat(&b, TableCSV)
+	if e := "Size\n512\n2K\n1M\n"; b.String() != e {
+		t.Errorf("csv: got %q expected %q", b.String(), e)
+	}
+}